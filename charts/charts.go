@@ -0,0 +1,14 @@
+package charts
+
+import (
+	"embed"
+	"path/filepath"
+)
+
+var (
+	// InternalChart embeds the internal charts which are rendered by the extension
+	//go:embed internal
+	InternalChart embed.FS
+	// ChartPathShootCsiDriverLvm is the path to the chart of the objects deployed into the shoot
+	ChartPathShootCsiDriverLvm = filepath.Join("internal", "shoot-csi-driver-lvm")
+)
@@ -33,5 +33,9 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 
 	options.optionAggregator.AddFlags(cmd.Flags())
 
+	cmd.AddCommand(NewRenderCommand())
+	cmd.AddCommand(NewInstallCommand())
+	cmd.AddCommand(NewUninstallCommand())
+
 	return cmd
 }
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configapi "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	configv1alpha1 "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config/validation"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	controller "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+type installOptions struct {
+	kubeconfigPath       string
+	configPath           string
+	controllerConfigPath string
+}
+
+// NewInstallCommand returns the command installing the driver into a cluster which is not managed by Gardener
+func NewInstallCommand() *cobra.Command {
+	options := &installOptions{}
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "installs csi-driver-lvm into a cluster outside of Gardener",
+		Long: "Applies the manifests deployed into a shoot for the given CsiDriverLvmConfig and ControllerConfiguration " +
+			"with server-side apply to the cluster of the kubeconfig. Objects of a previous installation which are no longer " +
+			"rendered are removed. The installation is refused while the old csi-lvm exists in the cluster. " +
+			"Storage classes with encryption are not supported.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&options.kubeconfigPath, "kubeconfig", "", "path to the kubeconfig of the cluster, defaults to the KUBECONFIG environment variable")
+	cmd.Flags().StringVar(&options.configPath, "config", "", "path to the CsiDriverLvmConfig")
+	cmd.Flags().StringVar(&options.controllerConfigPath, "controller-config", "", "path to the ControllerConfiguration providing the defaults")
+
+	return cmd
+}
+
+// NewUninstallCommand returns the command removing a standalone installation of the driver from a cluster
+func NewUninstallCommand() *cobra.Command {
+	var kubeconfigPath string
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "removes csi-driver-lvm installed by the install command from a cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			c, err := newClusterClient(kubeconfigPath)
+			if err != nil {
+				return err
+			}
+
+			return controller.Uninstall(cmd.Context(), log, c)
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfigPath, "kubeconfig", "", "path to the kubeconfig of the cluster, defaults to the KUBECONFIG environment variable")
+
+	return cmd
+}
+
+func (o *installOptions) run(ctx context.Context) error {
+	if o.configPath == "" {
+		return errors.New("config is not set")
+	}
+	if o.controllerConfigPath == "" {
+		return errors.New("controller config is not set")
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(install.AddToScheme(scheme))
+	utilruntime.Must(configapi.AddToScheme(scheme))
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
+	decoder := serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	if err := decodeFile(decoder, o.configPath, csidriverlvmConfig); err != nil {
+		return err
+	}
+
+	controllerConfig := configapi.ControllerConfiguration{}
+	if err := decodeFile(decoder, o.controllerConfigPath, &controllerConfig); err != nil {
+		return err
+	}
+	if errs := validation.ValidateConfiguration(&controllerConfig); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	c, err := newClusterClient(o.kubeconfigPath)
+	if err != nil {
+		return err
+	}
+
+	return controller.Install(ctx, log, c, controller.InstallOptions{
+		Config:           csidriverlvmConfig,
+		ControllerConfig: controllerConfig,
+	})
+}
+
+// newClusterClient returns a client for the cluster of the kubeconfig, the kubeconfig is loaded like by kubectl
+func newClusterClient(kubeconfigPath string) (client.Client, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfigPath
+
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	c, err := client.New(restConfig, client.Options{Scheme: kubernetes.ShootScheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	return c, nil
+}
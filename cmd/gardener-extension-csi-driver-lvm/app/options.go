@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -14,9 +15,13 @@ import (
 	heartbeatcmd "github.com/gardener/gardener/extensions/pkg/controller/heartbeat/cmd"
 	csidriverlvmcmd "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/cmd"
 	controller "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+	oscwebhook "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/webhook/operatingsystemconfig"
+	provisionerwebhook "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/webhook/provisioner"
 
 	controllercmd "github.com/gardener/gardener/extensions/pkg/controller/cmd"
 	"github.com/gardener/gardener/extensions/pkg/util"
+	webhookcmd "github.com/gardener/gardener/extensions/pkg/webhook/cmd"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	ghealth "github.com/gardener/gardener/pkg/healthz"
 	componentbaseconfig "k8s.io/component-base/config"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,6 +43,7 @@ type Options struct {
 	healthOptions       *controllercmd.ControllerOptions
 	controllerSwitches  *controllercmd.SwitchOptions
 	reconcileOptions    *controllercmd.ReconcilerOptions
+	webhookOptions      *webhookcmd.AddToManagerOptions
 	optionAggregator    controllercmd.OptionAggregator
 }
 
@@ -52,6 +58,8 @@ func NewOptions() *Options {
 			LeaderElectionNamespace: os.Getenv("LEADER_ELECTION_NAMESPACE"),
 			MetricsBindAddress:      ":8080",
 			HealthBindAddress:       ":8081",
+			WebhookServerPort:       10250,
+			WebhookCertDir:          "/tmp/gardener-extensions-cert",
 		},
 
 		// options for the controlplane controller
@@ -71,6 +79,17 @@ func NewOptions() *Options {
 		},
 		controllerSwitches: csidriverlvmcmd.ControllerSwitchOptions(),
 		reconcileOptions:   &controllercmd.ReconcilerOptions{},
+
+		// options for the webhooks mutating the seed and the shoots
+		webhookOptions: webhookcmd.NewAddToManagerOptions(
+			controller.Type,
+			v1alpha1.ShootCsiDriverLvmWebhooksResourceName,
+			map[string]string{v1beta1constants.LabelExtensionExtensionTypePrefix + controller.Type: "true"},
+			&webhookcmd.ServerOptions{
+				Namespace: os.Getenv("WEBHOOK_CONFIG_NAMESPACE"),
+			},
+			csidriverlvmcmd.WebhookSwitchOptions(),
+		),
 	}
 
 	options.optionAggregator = controllercmd.NewOptionAggregator(
@@ -83,6 +102,7 @@ func NewOptions() *Options {
 		controllercmd.PrefixOption("healthcheck-", options.healthOptions),
 		options.controllerSwitches,
 		options.reconcileOptions,
+		options.webhookOptions,
 	)
 
 	return options
@@ -131,11 +151,21 @@ func (options *Options) run(ctx context.Context) error {
 
 	ctrlConfig := options.csidriverlvmOptions.Completed()
 	ctrlConfig.Apply(&controller.DefaultAddOptions.Config)
+	ctrlConfig.Apply(&oscwebhook.DefaultAddOptions.Config)
+	ctrlConfig.Apply(&provisionerwebhook.DefaultAddOptions.Config)
 
 	options.controllerOptions.Completed().Apply(&controller.DefaultAddOptions.ControllerOptions)
 	options.reconcileOptions.Completed().Apply(&controller.DefaultAddOptions.IgnoreOperationAnnotation)
 	options.heartbeatOptions.Completed().Apply(&heartbeatcontroller.DefaultAddOptions)
 
+	// the webhooks are added first, the actuator deploys the configuration of the webhooks into the shoots
+	shootWebhookConfig, err := options.webhookOptions.Completed().AddToManager(ctx, mgr, nil)
+	if err != nil {
+		return fmt.Errorf("could not add webhooks to manager: %w", err)
+	}
+	controller.DefaultAddOptions.ShootWebhookConfig = shootWebhookConfig
+	log.Info("added webhooks to manager")
+
 	if err := options.controllerSwitches.Completed().AddToManager(ctx, mgr); err != nil {
 		return fmt.Errorf("could not add controllers to manager: %w", err)
 	}
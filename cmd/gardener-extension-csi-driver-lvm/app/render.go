@@ -0,0 +1,147 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	gardenerextensions "github.com/gardener/gardener/pkg/extensions"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	configapi "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	configv1alpha1 "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config/validation"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	controller "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+type renderOptions struct {
+	configPath           string
+	controllerConfigPath string
+	clusterPath          string
+}
+
+// NewRenderCommand returns the command printing the manifests deployed into a shoot without a Gardener environment
+func NewRenderCommand() *cobra.Command {
+	options := &renderOptions{}
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "prints the manifests deployed into a shoot for the given configuration",
+		Long: "Renders the manifests deployed into a shoot from a CsiDriverLvmConfig, a ControllerConfiguration and an optional Cluster. " +
+			"The configuration is validated the same way as by the extension controller. The command runs offline, " +
+			"the encryption keys referenced by storage classes are replaced by a placeholder.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return options.run(cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&options.configPath, "config", "", "path to the CsiDriverLvmConfig of the shoot")
+	cmd.Flags().StringVar(&options.controllerConfigPath, "controller-config", "", "path to the ControllerConfiguration of the extension")
+	cmd.Flags().StringVar(&options.clusterPath, "cluster", "", "path to a snapshot of the Cluster of the shoot (optional)")
+
+	return cmd
+}
+
+func (o *renderOptions) run(out io.Writer) error {
+	if o.configPath == "" {
+		return errors.New("config is not set")
+	}
+	if o.controllerConfigPath == "" {
+		return errors.New("controller config is not set")
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(install.AddToScheme(scheme))
+	utilruntime.Must(configapi.AddToScheme(scheme))
+	utilruntime.Must(configv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(extensionsv1alpha1.AddToScheme(scheme))
+	decoder := serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	if err := decodeFile(decoder, o.configPath, csidriverlvmConfig); err != nil {
+		return err
+	}
+
+	controllerConfig := configapi.ControllerConfiguration{}
+	if err := decodeFile(decoder, o.controllerConfigPath, &controllerConfig); err != nil {
+		return err
+	}
+	if errs := validation.ValidateConfiguration(&controllerConfig); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	var cluster *extensionscontroller.Cluster
+	if o.clusterPath != "" {
+		var err error
+		cluster, err = readCluster(decoder, o.clusterPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	managedResources, err := controller.Render(log, controller.RenderOptions{
+		Config:           csidriverlvmConfig,
+		ControllerConfig: controllerConfig,
+		Cluster:          cluster,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, managedResource := range managedResources {
+		for _, name := range slices.Sorted(maps.Keys(managedResource.Data)) {
+			if _, err := fmt.Fprintf(out, "---\n# Source: %s/%s\n%s", managedResource.Name, name, managedResource.Data[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func decodeFile(decoder runtime.Decoder, path string, into runtime.Object) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := decoder.Decode(data, nil, into); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readCluster reads a Cluster snapshot, e.g. from "kubectl get cluster -o yaml"
+func readCluster(decoder runtime.Decoder, path string) (*extensionscontroller.Cluster, error) {
+	cluster := &extensionsv1alpha1.Cluster{}
+	if err := decodeFile(decoder, path, cluster); err != nil {
+		return nil, err
+	}
+
+	cloudProfile, err := gardenerextensions.CloudProfileFromCluster(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cloud profile of cluster: %w", err)
+	}
+	seed, err := gardenerextensions.SeedFromCluster(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode seed of cluster: %w", err)
+	}
+	shoot, err := gardenerextensions.ShootFromCluster(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode shoot of cluster: %w", err)
+	}
+
+	return &extensionscontroller.Cluster{ObjectMeta: cluster.ObjectMeta, CloudProfile: cloudProfile, Seed: seed, Shoot: shoot}, nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	healthcheckconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
@@ -15,9 +16,123 @@ type ControllerConfiguration struct {
 	// DefaultDevicePattern can be used to configure the glob pattern for the devices used by the LVM driver
 	DefaultDevicePattern *string
 
-	// DefaultHostWritePath can be used to configure the default path for the host write path - used on read-only filesystems (Talos  OS "/var/etc/lvm")
+	// DefaultHostWritePath can be used to configure the default path for the host write path of worker pools without machine image defaults
 	DefaultHostWritePath *string
 
+	// AllowedFsTypes contains the filesystem types shoot owners are allowed to configure for storage classes
+	AllowedFsTypes []string
+
+	// AllowedMountOptions contains the mount options shoot owners are allowed to configure for storage classes
+	AllowedMountOptions []string
+
+	// AllowGeometry allows shoot owners to configure the stripes, stripeSize and mirrors of storage classes, it may only be
+	// enabled if the csi-driver-lvm images of the image vector read these parameters as other images ignore them
+	AllowGeometry *bool
+
+	// AllowThinAndRaid allows shoot owners to configure storage classes of the types thin, raid1, raid5 and raid10, it may only
+	// be enabled if the csi-driver-lvm images of the image vector support these types
+	AllowThinAndRaid *bool
+
+	// AllowEncryption allows shoot owners to configure LUKS encrypted storage classes, it may only be enabled if the
+	// csi-driver-lvm images of the image vector support encryption as other images create plaintext volumes
+	AllowEncryption *bool
+
+	// AllowedPatches contains the kinds and fields of the objects deployed into the shoot which shoot owners are allowed
+	// to patch, patches are rejected unless they are allowed
+	AllowedPatches []AllowedPatch
+
+	// MachineImageDefaults contains node specific defaults for worker pools running the machine image with the given name
+	MachineImageDefaults []MachineImageDefaults
+
+	// DefaultPaths contains the host paths used by the driver unless the shoot or the machine image defaults configure them
+	DefaultPaths *Paths
+
+	// RolloutPolicy can be used to roll out the images of the image vector to a subset of the shoots first, shoots can
+	// also be selected by annotating their Cluster with "csi-driver-lvm.metal.extensions.gardener.cloud/canary=true"
+	RolloutPolicy *RolloutPolicy
+
+	// PurposeProfiles contains the defaults for shoots with the given purpose, settings configured in the shoot take precedence
+	PurposeProfiles []PurposeProfile
+
+	// HealthTimeout enables waiting for the managed resources deployed into the shoot to become healthy, the
+	// reconciliation fails if they are not healthy within the timeout
+	HealthTimeout *metav1.Duration
+
 	// HealthCheckConfig is the config for the health check controller
 	HealthCheckConfig *healthcheckconfig.HealthCheckConfig
 }
+
+// AllowedPatch allows patching fields of the objects of a kind deployed into the shoot
+type AllowedPatch struct {
+	// Kind is the kind of the objects which may be patched (e.g. "DaemonSet")
+	Kind string
+
+	// Paths contains the JSON pointers of the fields which may be patched including the fields below them, "*" matches
+	// any list index or key (e.g. "/metadata/annotations" or "/spec/template/spec/containers/*/env")
+	Paths []string
+}
+
+// MachineImageDefaults contains the node specific defaults for a machine image
+type MachineImageDefaults struct {
+	// Name is the name of the machine image as used in the worker pools of the shoot (e.g. "talos")
+	Name string
+
+	// HostWritePath is the host write path used for worker pools with this machine image unless the shoot configures one
+	HostWritePath *string
+
+	// KubeletRootDir is the root directory of the kubelet on worker pools with this machine image
+	KubeletRootDir *string
+}
+
+// Paths contains the default host paths used by the driver on the nodes
+type Paths struct {
+	// KubeletRootDir is the root directory of the kubelet (e.g. "/var/lib/kubelet")
+	KubeletRootDir *string
+
+	// PluginDir is the directory of the driver socket, it defaults to the "plugins/csi-driver-lvm" directory below the kubelet root directory
+	PluginDir *string
+
+	// RegistrationDir is the plugin registration directory of the kubelet, it defaults to the "plugins_registry" directory below the kubelet root directory
+	RegistrationDir *string
+
+	// DevDir is the directory containing the device files (e.g. "/dev")
+	DevDir *string
+
+	// ModulesDir is the directory containing the kernel modules (e.g. "/lib/modules")
+	ModulesDir *string
+}
+
+// RolloutPolicy selects the shoots receiving the images of the image vector first, the other shoots receive the stable
+// images or keep their previously deployed images until the images are promoted by changing the stable images or
+// removing the policy
+type RolloutPolicy struct {
+	// Percentage is the percentage of shoots receiving the images of the image vector, the shoots are selected by a hash of their namespace
+	Percentage *int32
+
+	// Purposes contains the purposes of the shoots receiving the images of the image vector (e.g. "evaluation")
+	Purposes []string
+
+	// StableImages contains the images by their name in the image vector which are deployed into shoots outside of the selection, images without a stable image keep their previously deployed image or receive the image of the image vector
+	StableImages map[string]string
+}
+
+// PurposeProfile contains the defaults for shoots with a purpose
+type PurposeProfile struct {
+	// Purpose is the purpose of the shoots (e.g. "production")
+	Purpose string
+
+	// ReclaimPolicy is the reclaim policy of the storage classes, either "Delete" or "Retain"
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy
+
+	// HighAvailability runs the controller with multiple replicas and leader election
+	HighAvailability *bool
+
+	// LogLevel is the log level of the csi sidecars
+	LogLevel *int32
+
+	// DefaultStorageClass is the name of the storage class marked as the default storage class of the shoot
+	DefaultStorageClass *string
+
+	// DeletionPolicy is the policy for the objects deployed into the shoot when the extension is removed, either "Delete" or "Retain"
+	DeletionPolicy *string
+}
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	healthcheckconfigv1alpha1 "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -15,11 +16,151 @@ type ControllerConfiguration struct {
 	// +optional
 	DefaultDevicePattern *string `json:"defaultDevicePattern,omitempty"`
 
-	// DefaultHostWritePath can be used to configure the default path for the host write path - used on read-only filesystems (Talos  OS "/var/etc/lvm")
+	// DefaultHostWritePath can be used to configure the default path for the host write path of worker pools without machine image defaults
 	// +optional
 	DefaultHostWritePath *string `json:"defaultHostWritePath,omitempty"`
 
+	// AllowedFsTypes contains the filesystem types shoot owners are allowed to configure for storage classes
+	// +optional
+	AllowedFsTypes []string `json:"allowedFsTypes,omitempty"`
+
+	// AllowedMountOptions contains the mount options shoot owners are allowed to configure for storage classes
+	// +optional
+	AllowedMountOptions []string `json:"allowedMountOptions,omitempty"`
+
+	// AllowGeometry allows shoot owners to configure the stripes, stripeSize and mirrors of storage classes, it may only be
+	// enabled if the csi-driver-lvm images of the image vector read these parameters as other images ignore them
+	// +optional
+	AllowGeometry *bool `json:"allowGeometry,omitempty"`
+
+	// AllowThinAndRaid allows shoot owners to configure storage classes of the types thin, raid1, raid5 and raid10, it may only
+	// be enabled if the csi-driver-lvm images of the image vector support these types
+	// +optional
+	AllowThinAndRaid *bool `json:"allowThinAndRaid,omitempty"`
+
+	// AllowEncryption allows shoot owners to configure LUKS encrypted storage classes, it may only be enabled if the
+	// csi-driver-lvm images of the image vector support encryption as other images create plaintext volumes
+	// +optional
+	AllowEncryption *bool `json:"allowEncryption,omitempty"`
+
+	// AllowedPatches contains the kinds and fields of the objects deployed into the shoot which shoot owners are allowed
+	// to patch, patches are rejected unless they are allowed
+	// +optional
+	AllowedPatches []AllowedPatch `json:"allowedPatches,omitempty"`
+
+	// MachineImageDefaults contains node specific defaults for worker pools running the machine image with the given name
+	// +optional
+	MachineImageDefaults []MachineImageDefaults `json:"machineImageDefaults,omitempty"`
+
+	// DefaultPaths contains the host paths used by the driver unless the shoot or the machine image defaults configure them
+	// +optional
+	DefaultPaths *Paths `json:"defaultPaths,omitempty"`
+
+	// RolloutPolicy can be used to roll out the images of the image vector to a subset of the shoots first, shoots can
+	// also be selected by annotating their Cluster with "csi-driver-lvm.metal.extensions.gardener.cloud/canary=true"
+	// +optional
+	RolloutPolicy *RolloutPolicy `json:"rolloutPolicy,omitempty"`
+
+	// PurposeProfiles contains the defaults for shoots with the given purpose, settings configured in the shoot take precedence
+	// +optional
+	PurposeProfiles []PurposeProfile `json:"purposeProfiles,omitempty"`
+
+	// HealthTimeout enables waiting for the managed resources deployed into the shoot to become healthy, the
+	// reconciliation fails if they are not healthy within the timeout
+	// +optional
+	HealthTimeout *metav1.Duration `json:"healthTimeout,omitempty"`
+
 	// HealthCheckConfig is the config for the health check controller
 	// +optional
 	HealthCheckConfig *healthcheckconfigv1alpha1.HealthCheckConfig `json:"healthCheckConfig,omitempty"`
 }
+
+// AllowedPatch allows patching fields of the objects of a kind deployed into the shoot
+type AllowedPatch struct {
+	// Kind is the kind of the objects which may be patched (e.g. "DaemonSet")
+	Kind string `json:"kind"`
+
+	// Paths contains the JSON pointers of the fields which may be patched including the fields below them, "*" matches
+	// any list index or key (e.g. "/metadata/annotations" or "/spec/template/spec/containers/*/env")
+	Paths []string `json:"paths"`
+}
+
+// MachineImageDefaults contains the node specific defaults for a machine image
+type MachineImageDefaults struct {
+	// Name is the name of the machine image as used in the worker pools of the shoot (e.g. "talos")
+	Name string `json:"name"`
+
+	// HostWritePath is the host write path used for worker pools with this machine image unless the shoot configures one
+	// +optional
+	HostWritePath *string `json:"hostWritePath,omitempty"`
+
+	// KubeletRootDir is the root directory of the kubelet on worker pools with this machine image
+	// +optional
+	KubeletRootDir *string `json:"kubeletRootDir,omitempty"`
+}
+
+// Paths contains the default host paths used by the driver on the nodes
+type Paths struct {
+	// KubeletRootDir is the root directory of the kubelet (e.g. "/var/lib/kubelet")
+	// +optional
+	KubeletRootDir *string `json:"kubeletRootDir,omitempty"`
+
+	// PluginDir is the directory of the driver socket, it defaults to the "plugins/csi-driver-lvm" directory below the kubelet root directory
+	// +optional
+	PluginDir *string `json:"pluginDir,omitempty"`
+
+	// RegistrationDir is the plugin registration directory of the kubelet, it defaults to the "plugins_registry" directory below the kubelet root directory
+	// +optional
+	RegistrationDir *string `json:"registrationDir,omitempty"`
+
+	// DevDir is the directory containing the device files (e.g. "/dev")
+	// +optional
+	DevDir *string `json:"devDir,omitempty"`
+
+	// ModulesDir is the directory containing the kernel modules (e.g. "/lib/modules")
+	// +optional
+	ModulesDir *string `json:"modulesDir,omitempty"`
+}
+
+// RolloutPolicy selects the shoots receiving the images of the image vector first, the other shoots receive the stable
+// images or keep their previously deployed images until the images are promoted by changing the stable images or
+// removing the policy
+type RolloutPolicy struct {
+	// Percentage is the percentage of shoots receiving the images of the image vector, the shoots are selected by a hash of their namespace
+	// +optional
+	Percentage *int32 `json:"percentage,omitempty"`
+
+	// Purposes contains the purposes of the shoots receiving the images of the image vector (e.g. "evaluation")
+	// +optional
+	Purposes []string `json:"purposes,omitempty"`
+
+	// StableImages contains the images by their name in the image vector which are deployed into shoots outside of the selection, images without a stable image keep their previously deployed image or receive the image of the image vector
+	// +optional
+	StableImages map[string]string `json:"stableImages,omitempty"`
+}
+
+// PurposeProfile contains the defaults for shoots with a purpose
+type PurposeProfile struct {
+	// Purpose is the purpose of the shoots (e.g. "production")
+	Purpose string `json:"purpose"`
+
+	// ReclaimPolicy is the reclaim policy of the storage classes, either "Delete" or "Retain"
+	// +optional
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+
+	// HighAvailability runs the controller with multiple replicas and leader election
+	// +optional
+	HighAvailability *bool `json:"highAvailability,omitempty"`
+
+	// LogLevel is the log level of the csi sidecars
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+
+	// DefaultStorageClass is the name of the storage class marked as the default storage class of the shoot
+	// +optional
+	DefaultStorageClass *string `json:"defaultStorageClass,omitempty"`
+
+	// DeletionPolicy is the policy for the objects deployed into the shoot when the extension is removed, either "Delete" or "Retain"
+	// +optional
+	DeletionPolicy *string `json:"deletionPolicy,omitempty"`
+}
@@ -15,6 +15,8 @@ import (
 	apisconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
 	configv1alpha1 "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
 	config "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -26,6 +28,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*AllowedPatch)(nil), (*config.AllowedPatch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_AllowedPatch_To_config_AllowedPatch(a.(*AllowedPatch), b.(*config.AllowedPatch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.AllowedPatch)(nil), (*AllowedPatch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_AllowedPatch_To_v1alpha1_AllowedPatch(a.(*config.AllowedPatch), b.(*AllowedPatch), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ControllerConfiguration)(nil), (*config.ControllerConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ControllerConfiguration_To_config_ControllerConfiguration(a.(*ControllerConfiguration), b.(*config.ControllerConfiguration), scope)
 	}); err != nil {
@@ -36,12 +48,85 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*MachineImageDefaults)(nil), (*config.MachineImageDefaults)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MachineImageDefaults_To_config_MachineImageDefaults(a.(*MachineImageDefaults), b.(*config.MachineImageDefaults), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.MachineImageDefaults)(nil), (*MachineImageDefaults)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_MachineImageDefaults_To_v1alpha1_MachineImageDefaults(a.(*config.MachineImageDefaults), b.(*MachineImageDefaults), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Paths)(nil), (*config.Paths)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Paths_To_config_Paths(a.(*Paths), b.(*config.Paths), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.Paths)(nil), (*Paths)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_Paths_To_v1alpha1_Paths(a.(*config.Paths), b.(*Paths), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PurposeProfile)(nil), (*config.PurposeProfile)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PurposeProfile_To_config_PurposeProfile(a.(*PurposeProfile), b.(*config.PurposeProfile), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PurposeProfile)(nil), (*PurposeProfile)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PurposeProfile_To_v1alpha1_PurposeProfile(a.(*config.PurposeProfile), b.(*PurposeProfile), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*RolloutPolicy)(nil), (*config.RolloutPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_RolloutPolicy_To_config_RolloutPolicy(a.(*RolloutPolicy), b.(*config.RolloutPolicy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.RolloutPolicy)(nil), (*RolloutPolicy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_RolloutPolicy_To_v1alpha1_RolloutPolicy(a.(*config.RolloutPolicy), b.(*RolloutPolicy), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
+func autoConvert_v1alpha1_AllowedPatch_To_config_AllowedPatch(in *AllowedPatch, out *config.AllowedPatch, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Paths = *(*[]string)(unsafe.Pointer(&in.Paths))
+	return nil
+}
+
+// Convert_v1alpha1_AllowedPatch_To_config_AllowedPatch is an autogenerated conversion function.
+func Convert_v1alpha1_AllowedPatch_To_config_AllowedPatch(in *AllowedPatch, out *config.AllowedPatch, s conversion.Scope) error {
+	return autoConvert_v1alpha1_AllowedPatch_To_config_AllowedPatch(in, out, s)
+}
+
+func autoConvert_config_AllowedPatch_To_v1alpha1_AllowedPatch(in *config.AllowedPatch, out *AllowedPatch, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Paths = *(*[]string)(unsafe.Pointer(&in.Paths))
+	return nil
+}
+
+// Convert_config_AllowedPatch_To_v1alpha1_AllowedPatch is an autogenerated conversion function.
+func Convert_config_AllowedPatch_To_v1alpha1_AllowedPatch(in *config.AllowedPatch, out *AllowedPatch, s conversion.Scope) error {
+	return autoConvert_config_AllowedPatch_To_v1alpha1_AllowedPatch(in, out, s)
+}
+
 func autoConvert_v1alpha1_ControllerConfiguration_To_config_ControllerConfiguration(in *ControllerConfiguration, out *config.ControllerConfiguration, s conversion.Scope) error {
 	out.DefaultDevicePattern = (*string)(unsafe.Pointer(in.DefaultDevicePattern))
 	out.DefaultHostWritePath = (*string)(unsafe.Pointer(in.DefaultHostWritePath))
+	out.AllowedFsTypes = *(*[]string)(unsafe.Pointer(&in.AllowedFsTypes))
+	out.AllowedMountOptions = *(*[]string)(unsafe.Pointer(&in.AllowedMountOptions))
+	out.AllowGeometry = (*bool)(unsafe.Pointer(in.AllowGeometry))
+	out.AllowThinAndRaid = (*bool)(unsafe.Pointer(in.AllowThinAndRaid))
+	out.AllowEncryption = (*bool)(unsafe.Pointer(in.AllowEncryption))
+	out.AllowedPatches = *(*[]config.AllowedPatch)(unsafe.Pointer(&in.AllowedPatches))
+	out.MachineImageDefaults = *(*[]config.MachineImageDefaults)(unsafe.Pointer(&in.MachineImageDefaults))
+	out.DefaultPaths = (*config.Paths)(unsafe.Pointer(in.DefaultPaths))
+	out.RolloutPolicy = (*config.RolloutPolicy)(unsafe.Pointer(in.RolloutPolicy))
+	out.PurposeProfiles = *(*[]config.PurposeProfile)(unsafe.Pointer(&in.PurposeProfiles))
+	out.HealthTimeout = (*v1.Duration)(unsafe.Pointer(in.HealthTimeout))
 	out.HealthCheckConfig = (*apisconfig.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
 	return nil
 }
@@ -54,6 +139,17 @@ func Convert_v1alpha1_ControllerConfiguration_To_config_ControllerConfiguration(
 func autoConvert_config_ControllerConfiguration_To_v1alpha1_ControllerConfiguration(in *config.ControllerConfiguration, out *ControllerConfiguration, s conversion.Scope) error {
 	out.DefaultDevicePattern = (*string)(unsafe.Pointer(in.DefaultDevicePattern))
 	out.DefaultHostWritePath = (*string)(unsafe.Pointer(in.DefaultHostWritePath))
+	out.AllowedFsTypes = *(*[]string)(unsafe.Pointer(&in.AllowedFsTypes))
+	out.AllowedMountOptions = *(*[]string)(unsafe.Pointer(&in.AllowedMountOptions))
+	out.AllowGeometry = (*bool)(unsafe.Pointer(in.AllowGeometry))
+	out.AllowThinAndRaid = (*bool)(unsafe.Pointer(in.AllowThinAndRaid))
+	out.AllowEncryption = (*bool)(unsafe.Pointer(in.AllowEncryption))
+	out.AllowedPatches = *(*[]AllowedPatch)(unsafe.Pointer(&in.AllowedPatches))
+	out.MachineImageDefaults = *(*[]MachineImageDefaults)(unsafe.Pointer(&in.MachineImageDefaults))
+	out.DefaultPaths = (*Paths)(unsafe.Pointer(in.DefaultPaths))
+	out.RolloutPolicy = (*RolloutPolicy)(unsafe.Pointer(in.RolloutPolicy))
+	out.PurposeProfiles = *(*[]PurposeProfile)(unsafe.Pointer(&in.PurposeProfiles))
+	out.HealthTimeout = (*v1.Duration)(unsafe.Pointer(in.HealthTimeout))
 	out.HealthCheckConfig = (*configv1alpha1.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
 	return nil
 }
@@ -62,3 +158,109 @@ func autoConvert_config_ControllerConfiguration_To_v1alpha1_ControllerConfigurat
 func Convert_config_ControllerConfiguration_To_v1alpha1_ControllerConfiguration(in *config.ControllerConfiguration, out *ControllerConfiguration, s conversion.Scope) error {
 	return autoConvert_config_ControllerConfiguration_To_v1alpha1_ControllerConfiguration(in, out, s)
 }
+
+func autoConvert_v1alpha1_MachineImageDefaults_To_config_MachineImageDefaults(in *MachineImageDefaults, out *config.MachineImageDefaults, s conversion.Scope) error {
+	out.Name = in.Name
+	out.HostWritePath = (*string)(unsafe.Pointer(in.HostWritePath))
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	return nil
+}
+
+// Convert_v1alpha1_MachineImageDefaults_To_config_MachineImageDefaults is an autogenerated conversion function.
+func Convert_v1alpha1_MachineImageDefaults_To_config_MachineImageDefaults(in *MachineImageDefaults, out *config.MachineImageDefaults, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MachineImageDefaults_To_config_MachineImageDefaults(in, out, s)
+}
+
+func autoConvert_config_MachineImageDefaults_To_v1alpha1_MachineImageDefaults(in *config.MachineImageDefaults, out *MachineImageDefaults, s conversion.Scope) error {
+	out.Name = in.Name
+	out.HostWritePath = (*string)(unsafe.Pointer(in.HostWritePath))
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	return nil
+}
+
+// Convert_config_MachineImageDefaults_To_v1alpha1_MachineImageDefaults is an autogenerated conversion function.
+func Convert_config_MachineImageDefaults_To_v1alpha1_MachineImageDefaults(in *config.MachineImageDefaults, out *MachineImageDefaults, s conversion.Scope) error {
+	return autoConvert_config_MachineImageDefaults_To_v1alpha1_MachineImageDefaults(in, out, s)
+}
+
+func autoConvert_v1alpha1_Paths_To_config_Paths(in *Paths, out *config.Paths, s conversion.Scope) error {
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	out.PluginDir = (*string)(unsafe.Pointer(in.PluginDir))
+	out.RegistrationDir = (*string)(unsafe.Pointer(in.RegistrationDir))
+	out.DevDir = (*string)(unsafe.Pointer(in.DevDir))
+	out.ModulesDir = (*string)(unsafe.Pointer(in.ModulesDir))
+	return nil
+}
+
+// Convert_v1alpha1_Paths_To_config_Paths is an autogenerated conversion function.
+func Convert_v1alpha1_Paths_To_config_Paths(in *Paths, out *config.Paths, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Paths_To_config_Paths(in, out, s)
+}
+
+func autoConvert_config_Paths_To_v1alpha1_Paths(in *config.Paths, out *Paths, s conversion.Scope) error {
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	out.PluginDir = (*string)(unsafe.Pointer(in.PluginDir))
+	out.RegistrationDir = (*string)(unsafe.Pointer(in.RegistrationDir))
+	out.DevDir = (*string)(unsafe.Pointer(in.DevDir))
+	out.ModulesDir = (*string)(unsafe.Pointer(in.ModulesDir))
+	return nil
+}
+
+// Convert_config_Paths_To_v1alpha1_Paths is an autogenerated conversion function.
+func Convert_config_Paths_To_v1alpha1_Paths(in *config.Paths, out *Paths, s conversion.Scope) error {
+	return autoConvert_config_Paths_To_v1alpha1_Paths(in, out, s)
+}
+
+func autoConvert_v1alpha1_PurposeProfile_To_config_PurposeProfile(in *PurposeProfile, out *config.PurposeProfile, s conversion.Scope) error {
+	out.Purpose = in.Purpose
+	out.ReclaimPolicy = (*corev1.PersistentVolumeReclaimPolicy)(unsafe.Pointer(in.ReclaimPolicy))
+	out.HighAvailability = (*bool)(unsafe.Pointer(in.HighAvailability))
+	out.LogLevel = (*int32)(unsafe.Pointer(in.LogLevel))
+	out.DefaultStorageClass = (*string)(unsafe.Pointer(in.DefaultStorageClass))
+	out.DeletionPolicy = (*string)(unsafe.Pointer(in.DeletionPolicy))
+	return nil
+}
+
+// Convert_v1alpha1_PurposeProfile_To_config_PurposeProfile is an autogenerated conversion function.
+func Convert_v1alpha1_PurposeProfile_To_config_PurposeProfile(in *PurposeProfile, out *config.PurposeProfile, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PurposeProfile_To_config_PurposeProfile(in, out, s)
+}
+
+func autoConvert_config_PurposeProfile_To_v1alpha1_PurposeProfile(in *config.PurposeProfile, out *PurposeProfile, s conversion.Scope) error {
+	out.Purpose = in.Purpose
+	out.ReclaimPolicy = (*corev1.PersistentVolumeReclaimPolicy)(unsafe.Pointer(in.ReclaimPolicy))
+	out.HighAvailability = (*bool)(unsafe.Pointer(in.HighAvailability))
+	out.LogLevel = (*int32)(unsafe.Pointer(in.LogLevel))
+	out.DefaultStorageClass = (*string)(unsafe.Pointer(in.DefaultStorageClass))
+	out.DeletionPolicy = (*string)(unsafe.Pointer(in.DeletionPolicy))
+	return nil
+}
+
+// Convert_config_PurposeProfile_To_v1alpha1_PurposeProfile is an autogenerated conversion function.
+func Convert_config_PurposeProfile_To_v1alpha1_PurposeProfile(in *config.PurposeProfile, out *PurposeProfile, s conversion.Scope) error {
+	return autoConvert_config_PurposeProfile_To_v1alpha1_PurposeProfile(in, out, s)
+}
+
+func autoConvert_v1alpha1_RolloutPolicy_To_config_RolloutPolicy(in *RolloutPolicy, out *config.RolloutPolicy, s conversion.Scope) error {
+	out.Percentage = (*int32)(unsafe.Pointer(in.Percentage))
+	out.Purposes = *(*[]string)(unsafe.Pointer(&in.Purposes))
+	out.StableImages = *(*map[string]string)(unsafe.Pointer(&in.StableImages))
+	return nil
+}
+
+// Convert_v1alpha1_RolloutPolicy_To_config_RolloutPolicy is an autogenerated conversion function.
+func Convert_v1alpha1_RolloutPolicy_To_config_RolloutPolicy(in *RolloutPolicy, out *config.RolloutPolicy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_RolloutPolicy_To_config_RolloutPolicy(in, out, s)
+}
+
+func autoConvert_config_RolloutPolicy_To_v1alpha1_RolloutPolicy(in *config.RolloutPolicy, out *RolloutPolicy, s conversion.Scope) error {
+	out.Percentage = (*int32)(unsafe.Pointer(in.Percentage))
+	out.Purposes = *(*[]string)(unsafe.Pointer(&in.Purposes))
+	out.StableImages = *(*map[string]string)(unsafe.Pointer(&in.StableImages))
+	return nil
+}
+
+// Convert_config_RolloutPolicy_To_v1alpha1_RolloutPolicy is an autogenerated conversion function.
+func Convert_config_RolloutPolicy_To_v1alpha1_RolloutPolicy(in *config.RolloutPolicy, out *RolloutPolicy, s conversion.Scope) error {
+	return autoConvert_config_RolloutPolicy_To_v1alpha1_RolloutPolicy(in, out, s)
+}
@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"path/filepath"
+	"slices"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/imagevector"
+)
+
+// shootPurposes are the purposes of shoots known to gardener
+var shootPurposes = []string{
+	string(gardencorev1beta1.ShootPurposeEvaluation),
+	string(gardencorev1beta1.ShootPurposeTesting),
+	string(gardencorev1beta1.ShootPurposeDevelopment),
+	string(gardencorev1beta1.ShootPurposeProduction),
+	string(gardencorev1beta1.ShootPurposeInfrastructure),
+}
+
+// ValidateConfiguration validates the controller configuration of the extension
+func ValidateConfiguration(config *config.ControllerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if config.DefaultPaths != nil {
+		allErrs = append(allErrs, validatePaths(config.DefaultPaths, field.NewPath("defaultPaths"))...)
+	}
+	if config.RolloutPolicy != nil {
+		allErrs = append(allErrs, validateRolloutPolicy(config.RolloutPolicy, field.NewPath("rolloutPolicy"))...)
+	}
+
+	return allErrs
+}
+
+// validatePaths validates that the host paths are absolute, they are mounted into the pods of the shoot
+func validatePaths(paths *config.Paths, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for _, path := range []struct {
+		name  string
+		value *string
+	}{
+		{name: "kubeletRootDir", value: paths.KubeletRootDir},
+		{name: "pluginDir", value: paths.PluginDir},
+		{name: "registrationDir", value: paths.RegistrationDir},
+		{name: "devDir", value: paths.DevDir},
+		{name: "modulesDir", value: paths.ModulesDir},
+	} {
+		if path.value != nil && !filepath.IsAbs(*path.value) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(path.name), *path.value, "must be an absolute path"))
+		}
+	}
+
+	return allErrs
+}
+
+// validateRolloutPolicy validates that the policy selects shoots by known purposes and only pins images of the image vector
+func validateRolloutPolicy(policy *config.RolloutPolicy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if policy.Percentage != nil && (*policy.Percentage < 0 || *policy.Percentage > 100) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("percentage"), *policy.Percentage, "must be between 0 and 100"))
+	}
+
+	for i, purpose := range policy.Purposes {
+		if !slices.Contains(shootPurposes, purpose) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Child("purposes").Index(i), purpose, shootPurposes))
+		}
+	}
+
+	names := make([]string, 0, len(policy.StableImages))
+	for name := range policy.StableImages {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, err := imagevector.ImageVector().FindImage(name); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("stableImages").Key(name), policy.StableImages[name], "image is not contained in the image vector"))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,71 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+)
+
+func TestValidateConfiguration(t *testing.T) {
+	tt := []struct {
+		desc    string
+		config  config.ControllerConfiguration
+		wantErr string
+	}{
+		{
+			desc: "test empty config",
+		},
+		{
+			desc: "test absolute default paths",
+			config: config.ControllerConfiguration{DefaultPaths: &config.Paths{
+				KubeletRootDir:  ptr.To("/var/lib/kubelet"),
+				PluginDir:       ptr.To("/var/lib/kubelet/plugins/csi-driver-lvm"),
+				RegistrationDir: ptr.To("/var/lib/kubelet/plugins_registry"),
+				DevDir:          ptr.To("/dev"),
+				ModulesDir:      ptr.To("/lib/modules"),
+			}},
+		},
+		{
+			desc:    "test relative default plugin dir",
+			config:  config.ControllerConfiguration{DefaultPaths: &config.Paths{PluginDir: ptr.To("var/lib/kubelet/plugins/csi-driver-lvm")}},
+			wantErr: `defaultPaths.pluginDir: Invalid value: "var/lib/kubelet/plugins/csi-driver-lvm": must be an absolute path`,
+		},
+		{
+			desc: "test rollout policy",
+			config: config.ControllerConfiguration{RolloutPolicy: &config.RolloutPolicy{
+				Percentage:   ptr.To(int32(10)),
+				Purposes:     []string{"evaluation", "development"},
+				StableImages: map[string]string{"csi-driver-lvm": "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0"},
+			}},
+		},
+		{
+			desc:    "test rollout policy with percentage out of range",
+			config:  config.ControllerConfiguration{RolloutPolicy: &config.RolloutPolicy{Percentage: ptr.To(int32(110))}},
+			wantErr: `rolloutPolicy.percentage: Invalid value: 110: must be between 0 and 100`,
+		},
+		{
+			desc:    "test rollout policy with unknown purpose",
+			config:  config.ControllerConfiguration{RolloutPolicy: &config.RolloutPolicy{Purposes: []string{"staging"}}},
+			wantErr: `rolloutPolicy.purposes[0]: Unsupported value: "staging": supported values: "evaluation", "testing", "development", "production", "infrastructure"`,
+		},
+		{
+			desc:    "test rollout policy with unknown stable image",
+			config:  config.ControllerConfiguration{RolloutPolicy: &config.RolloutPolicy{StableImages: map[string]string{"csi-lvm": "ghcr.io/metal-stack/csi-lvm:v0.6.0"}}},
+			wantErr: `rolloutPolicy.stableImages[csi-lvm]: Invalid value: "ghcr.io/metal-stack/csi-lvm:v0.6.0": image is not contained in the image vector`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			errs := ValidateConfiguration(&tc.config)
+			if tc.wantErr == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			assert.EqualError(t, errs.ToAggregate(), tc.wantErr)
+		})
+	}
+}
@@ -11,9 +11,32 @@ package config
 
 import (
 	apisconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedPatch) DeepCopyInto(out *AllowedPatch) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedPatch.
+func (in *AllowedPatch) DeepCopy() *AllowedPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControllerConfiguration) DeepCopyInto(out *ControllerConfiguration) {
 	*out = *in
@@ -28,6 +51,67 @@ func (in *ControllerConfiguration) DeepCopyInto(out *ControllerConfiguration) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.AllowedFsTypes != nil {
+		in, out := &in.AllowedFsTypes, &out.AllowedFsTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedMountOptions != nil {
+		in, out := &in.AllowedMountOptions, &out.AllowedMountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowGeometry != nil {
+		in, out := &in.AllowGeometry, &out.AllowGeometry
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowThinAndRaid != nil {
+		in, out := &in.AllowThinAndRaid, &out.AllowThinAndRaid
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowEncryption != nil {
+		in, out := &in.AllowEncryption, &out.AllowEncryption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowedPatches != nil {
+		in, out := &in.AllowedPatches, &out.AllowedPatches
+		*out = make([]AllowedPatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MachineImageDefaults != nil {
+		in, out := &in.MachineImageDefaults, &out.MachineImageDefaults
+		*out = make([]MachineImageDefaults, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DefaultPaths != nil {
+		in, out := &in.DefaultPaths, &out.DefaultPaths
+		*out = new(Paths)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolloutPolicy != nil {
+		in, out := &in.RolloutPolicy, &out.RolloutPolicy
+		*out = new(RolloutPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PurposeProfiles != nil {
+		in, out := &in.PurposeProfiles, &out.PurposeProfiles
+		*out = make([]PurposeProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthTimeout != nil {
+		in, out := &in.HealthTimeout, &out.HealthTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	if in.HealthCheckConfig != nil {
 		in, out := &in.HealthCheckConfig, &out.HealthCheckConfig
 		*out = new(apisconfig.HealthCheckConfig)
@@ -53,3 +137,144 @@ func (in *ControllerConfiguration) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineImageDefaults) DeepCopyInto(out *MachineImageDefaults) {
+	*out = *in
+	if in.HostWritePath != nil {
+		in, out := &in.HostWritePath, &out.HostWritePath
+		*out = new(string)
+		**out = **in
+	}
+	if in.KubeletRootDir != nil {
+		in, out := &in.KubeletRootDir, &out.KubeletRootDir
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineImageDefaults.
+func (in *MachineImageDefaults) DeepCopy() *MachineImageDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineImageDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Paths) DeepCopyInto(out *Paths) {
+	*out = *in
+	if in.KubeletRootDir != nil {
+		in, out := &in.KubeletRootDir, &out.KubeletRootDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.PluginDir != nil {
+		in, out := &in.PluginDir, &out.PluginDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegistrationDir != nil {
+		in, out := &in.RegistrationDir, &out.RegistrationDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.DevDir != nil {
+		in, out := &in.DevDir, &out.DevDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.ModulesDir != nil {
+		in, out := &in.ModulesDir, &out.ModulesDir
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Paths.
+func (in *Paths) DeepCopy() *Paths {
+	if in == nil {
+		return nil
+	}
+	out := new(Paths)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PurposeProfile) DeepCopyInto(out *PurposeProfile) {
+	*out = *in
+	if in.ReclaimPolicy != nil {
+		in, out := &in.ReclaimPolicy, &out.ReclaimPolicy
+		*out = new(corev1.PersistentVolumeReclaimPolicy)
+		**out = **in
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultStorageClass != nil {
+		in, out := &in.DefaultStorageClass, &out.DefaultStorageClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PurposeProfile.
+func (in *PurposeProfile) DeepCopy() *PurposeProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(PurposeProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutPolicy) DeepCopyInto(out *RolloutPolicy) {
+	*out = *in
+	if in.Percentage != nil {
+		in, out := &in.Percentage, &out.Percentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Purposes != nil {
+		in, out := &in.Purposes, &out.Purposes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StableImages != nil {
+		in, out := &in.StableImages, &out.StableImages
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPolicy.
+func (in *RolloutPolicy) DeepCopy() *RolloutPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -1,7 +1,11 @@
 package csidriverlvm
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -13,6 +17,245 @@ type CsiDriverLvmConfig struct {
 	// DevicePattern can be used to configure the glob pattern for the devices used by the LVM driver
 	DevicePattern *string
 
-	// HostWritePath can be used to configure the host write path - used on read-only filesystems (Talos  OS "/var/etc/lvm")
+	// HostWritePath can be used to configure the host write path of all worker pools, by default it depends on the machine image (Talos OS "/var/etc/lvm")
 	HostWritePath *string
+
+	// StorageClasses can be used to customize the default storage classes or to add further storage classes, entries are matched by name
+	StorageClasses []StorageClass
+
+	// LvmConfig can be used to configure the lvmlocal.conf of the plugin and the provisioner pods
+	LvmConfig *LvmConfig
+
+	// LoopDevices can be used to create file backed loop devices on the nodes, only supported for shoots with purpose development
+	LoopDevices *LoopDevices
+
+	// Paths can be used to configure the host paths used by the driver, unset paths are defaulted by the operator
+	Paths *Paths
+
+	// HighAvailability runs the controller with multiple replicas and leader election, by default it is enabled if the
+	// control plane of the shoot is configured with a failure tolerance
+	HighAvailability *bool
+
+	// PluginUpdateStrategy configures how the plugin daemon sets are updated, it defaults to a rolling update with one unavailable node
+	PluginUpdateStrategy *PluginUpdateStrategy
+
+	// ReclaimPolicy is the reclaim policy of the storage classes, either "Delete" or "Retain", it defaults to the purpose profile of the operator or "Delete"
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy
+
+	// LogLevel is the log level of the csi sidecars, it defaults to the purpose profile of the operator or 5
+	LogLevel *int32
+
+	// DefaultStorageClass is the name of the storage class marked as the default storage class of the shoot
+	DefaultStorageClass *string
+
+	// DeletionPolicy is the policy for the objects deployed into the shoot when the extension is removed, "Delete" removes them and "Retain" keeps them, it defaults to the purpose profile of the operator or "Delete"
+	DeletionPolicy *string
+
+	// Patches can be used to patch the objects deployed into the shoot, the operator has to allow the kinds and fields of the patched objects
+	Patches []Patch
+}
+
+// Patch is a patch of an object deployed into the shoot
+type Patch struct {
+	// Kind is the kind of the patched object (e.g. "DaemonSet")
+	Kind string
+
+	// Name is the name of the patched object
+	Name string
+
+	// Type is the type of the patch, either "StrategicMerge" or "JSON"
+	Type *string
+
+	// Patch is the strategic merge patch or the JSON patch in JSON or YAML
+	Patch string
+}
+
+// PluginUpdateStrategy configures the update strategy of the plugin daemon sets
+type PluginUpdateStrategy struct {
+	// Type is the type of the update strategy, either "RollingUpdate" or "OnDelete" to restart the plugin only when the pods are deleted, e.g. during the node maintenance
+	Type *appsv1.DaemonSetUpdateStrategyType
+
+	// MaxUnavailable is the maximum number of nodes or percentage of nodes without an available plugin during a rolling update
+	MaxUnavailable *intstr.IntOrString
+
+	// MaxSurge is the maximum number of nodes or percentage of nodes running an updated plugin in addition to the old one during a rolling update
+	MaxSurge *intstr.IntOrString
+}
+
+// Paths configures the host paths used by the driver on the nodes
+type Paths struct {
+	// KubeletRootDir is the root directory of the kubelet (e.g. "/var/lib/kubelet")
+	KubeletRootDir *string
+
+	// PluginDir is the directory of the driver socket, it defaults to the "plugins/csi-driver-lvm" directory below the kubelet root directory
+	PluginDir *string
+
+	// RegistrationDir is the plugin registration directory of the kubelet, it defaults to the "plugins_registry" directory below the kubelet root directory
+	RegistrationDir *string
+
+	// DevDir is the directory containing the device files (e.g. "/dev")
+	DevDir *string
+
+	// ModulesDir is the directory containing the kernel modules (e.g. "/lib/modules")
+	ModulesDir *string
+}
+
+// LoopDevices configures the file backed loop devices created on the nodes of development shoots
+type LoopDevices struct {
+	// Count is the number of loop devices created on every node
+	Count int32
+
+	// Size is the size of the backing file of every loop device
+	Size resource.Quantity
+}
+
+// LvmConfig contains the supported settings of the lvmlocal.conf. The provisioner pods which create and remove the
+// logical volumes are started by the driver, they receive the lvmlocal.conf from a webhook in the shoot.
+type LvmConfig struct {
+	// DeviceFilter is the devices/filter setting, every entry accepts ("a|regex|") or rejects ("r|regex|") the matching devices
+	DeviceFilter []string
+
+	// IssueDiscards is the devices/issue_discards setting, discards are sent to the devices when logical volumes are removed
+	IssueDiscards *bool
+
+	// ThinPoolAutoextendThreshold is the activation/thin_pool_autoextend_threshold setting in percent, 100 disables the autoextension
+	ThinPoolAutoextendThreshold *int32
+
+	// ThinPoolAutoextendPercent is the activation/thin_pool_autoextend_percent setting
+	ThinPoolAutoextendPercent *int32
+}
+
+// StorageClass configures a storage class deployed into the shoot
+type StorageClass struct {
+	// Name is the name of the storage class
+	Name string
+
+	// Type is the LVM volume type of the storage class, required for storage classes which are not deployed by default
+	Type *string
+
+	// FsType can be used to configure the filesystem type of the volumes (e.g. "xfs" or "ext4")
+	FsType *string
+
+	// MountOptions can be used to configure the mount options of the volumes (e.g. "noatime" or "discard")
+	MountOptions []string
+
+	// Stripes can be used to configure the number of stripes of striped volumes, the pool needs at least as many devices
+	Stripes *int32
+
+	// StripeSize can be used to configure the stripe size of striped volumes, must be a power of two and at least 4Ki
+	StripeSize *resource.Quantity
+
+	// Mirrors can be used to configure the number of additional copies of mirrored volumes, the pool needs at least one device more
+	Mirrors *int32
+
+	// ThinPool can be used to configure the thin pool of thin provisioned volumes
+	ThinPool *ThinPool
+
+	// Encryption can be used to encrypt the volumes with LUKS
+	Encryption *Encryption
+}
+
+// ThinPool configures the thin pool backing thin provisioned volumes
+type ThinPool struct {
+	// SizePercentage is the size of the thin pool in percent of the volume group
+	SizePercentage *int32
+
+	// OvercommitRatio is the maximum ratio of the provisioned volume sizes to the size of the thin pool
+	OvercommitRatio *int32
+}
+
+// Encryption configures the LUKS encryption of volumes
+type Encryption struct {
+	// SecretResourceName is the name of the shoot resource (spec.resources) referencing the secret which contains the LUKS passphrase in the key "passphrase"
+	SecretResourceName string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CsiDriverLvmStatus contains information about the csi-driver-lvm deployed into the shoot
+type CsiDriverLvmStatus struct {
+	metav1.TypeMeta
+
+	// EncryptionKeys contains the state of the encryption keys deployed for the storage classes
+	EncryptionKeys []EncryptionKeyStatus
+
+	// WorkerPools contains the node specific settings resolved for the worker pools of the shoot
+	WorkerPools []WorkerPoolStatus
+
+	// PluginRollouts contains the rollout progress of the plugin daemon sets
+	PluginRollouts []PluginRolloutStatus
+
+	// Images contains the images deployed into the shoot by their name in the image vector, changed images are held
+	// back until the maintenance window of the shoot
+	Images map[string]string
+
+	// ImageSet is the image set deployed into the shoot, "canary" if the shoot is selected by the rollout policy of
+	// the operator, otherwise "stable"
+	ImageSet string
+
+	// OldCsiLvmAbsent is true once the absence of the old csi-lvm has been confirmed, the shoot is not probed again
+	// unless the extension is annotated with "csi-driver-lvm.metal.extensions.gardener.cloud/recheck-old-csi-lvm=true"
+	OldCsiLvmAbsent bool
+
+	// ManagedResources contains the health of the managed resources of the components deployed into the shoot
+	ManagedResources []ManagedResourceStatus
+}
+
+// ManagedResourceStatus contains the health of the managed resource of a component deployed into the shoot
+type ManagedResourceStatus struct {
+	// Name is the name of the managed resource
+	Name string
+
+	// Healthy is true if the resources of the managed resource are applied and healthy
+	Healthy bool
+
+	// Message describes why the managed resource is not healthy
+	Message string
+}
+
+// PluginRolloutStatus contains the rollout progress of a plugin daemon set
+type PluginRolloutStatus struct {
+	// Name is the name of the plugin daemon set
+	Name string
+
+	// DesiredNodes is the number of nodes which should run the plugin
+	DesiredNodes int32
+
+	// UpdatedNodes is the number of nodes running the updated plugin
+	UpdatedNodes int32
+
+	// AvailableNodes is the number of nodes running an available plugin
+	AvailableNodes int32
+}
+
+// WorkerPoolStatus contains the node specific settings used for a worker pool
+type WorkerPoolStatus struct {
+	// Name is the name of the worker pool
+	Name string
+
+	// MachineImage is the name of the machine image of the worker pool
+	MachineImage string
+
+	// HostWritePath is the host write path used on the nodes of the worker pool
+	HostWritePath string
+
+	// KubeletRootDir is the root directory of the kubelet on the nodes of the worker pool
+	KubeletRootDir string
+}
+
+// EncryptionKeyStatus tracks the rotation of the encryption key of a storage class, every key is deployed into its own
+// secret as the existing volumes keep referencing the key they were created with
+type EncryptionKeyStatus struct {
+	// StorageClass is the name of the storage class using the key
+	StorageClass string
+
+	// Checksum is the checksum of the current key deployed into the shoot keyed with a secret of the seed, it is empty
+	// if the storage class is not encrypted anymore
+	Checksum string
+
+	// LastRotationTime is the time the current key has been changed the last time
+	LastRotationTime *metav1.Time
+
+	// PreviousChecksums contains the checksums of the previous keys, they are kept in the shoot as long as volumes use them
+	PreviousChecksums []string
 }
@@ -40,6 +40,7 @@ func init() {
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CsiDriverLvmConfig{},
+		&CsiDriverLvmStatus{},
 	)
 	return nil
 }
@@ -2,15 +2,81 @@ package v1alpha1
 
 import (
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
 )
 
 const (
+	// ShootCsiDriverLvmResourceName is the name of the single managed resource deployed by previous versions of the
+	// extension, it is migrated to the managed resources of the components
 	ShootCsiDriverLvmResourceName = "extension-csi-driver-lvm"
+	// ShootCsiDriverLvmControllerResourceName is the name of the managed resource of the controller
+	ShootCsiDriverLvmControllerResourceName = "extension-csi-driver-lvm-controller"
+	// ShootCsiDriverLvmPluginResourceName is the name of the managed resource of the plugin
+	ShootCsiDriverLvmPluginResourceName = "extension-csi-driver-lvm-plugin"
+	// ShootCsiDriverLvmStorageClassesResourceName is the name of the managed resource of the storage classes
+	ShootCsiDriverLvmStorageClassesResourceName = "extension-csi-driver-lvm-storageclasses"
+	// ShootCsiDriverLvmWebhooksResourceName is the name of the managed resource of the webhooks in the shoot
+	ShootCsiDriverLvmWebhooksResourceName = "extension-csi-driver-lvm-shoot-webhooks"
+
+	// RecheckOldCsiLvmAnnotation can be set to "true" on the extension to probe the shoot for the old csi-lvm again
+	RecheckOldCsiLvmAnnotation = "csi-driver-lvm.metal.extensions.gardener.cloud/recheck-old-csi-lvm"
+	// ForceImageUpdateAnnotation can be set to "true" on the extension to roll out held back image updates outside of
+	// the maintenance window of the shoot
+	ForceImageUpdateAnnotation = "csi-driver-lvm.metal.extensions.gardener.cloud/force-image-update"
+)
+
+const (
+	// DeletionPolicyDelete removes the objects deployed into the shoot when the extension is removed
+	DeletionPolicyDelete = "Delete"
+	// DeletionPolicyRetain keeps the objects deployed into the shoot when the extension is removed
+	DeletionPolicyRetain = "Retain"
+)
+
+const (
+	// ImageSetCanary is the image set of shoots selected by the rollout policy, they receive the images of the image vector first
+	ImageSetCanary = "canary"
+	// ImageSetStable is the image set of shoots outside of the selection of the rollout policy
+	ImageSetStable = "stable"
 )
 
+const (
+	// VolumeTypeLinear is the LVM volume type for linear volumes
+	VolumeTypeLinear = "linear"
+	// VolumeTypeMirror is the LVM volume type for mirrored volumes
+	VolumeTypeMirror = "mirror"
+	// VolumeTypeStriped is the LVM volume type for striped volumes
+	VolumeTypeStriped = "striped"
+	// VolumeTypeThin is the LVM volume type for thin provisioned volumes
+	VolumeTypeThin = "thin"
+	// VolumeTypeRaid1 is the LVM volume type for dm-raid level 1 volumes
+	VolumeTypeRaid1 = "raid1"
+	// VolumeTypeRaid5 is the LVM volume type for dm-raid level 5 volumes
+	VolumeTypeRaid5 = "raid5"
+	// VolumeTypeRaid10 is the LVM volume type for dm-raid level 10 volumes
+	VolumeTypeRaid10 = "raid10"
+)
+
+const (
+	// PatchTypeStrategicMerge is the type of strategic merge patches
+	PatchTypeStrategicMerge = "StrategicMerge"
+	// PatchTypeJSON is the type of JSON patches (RFC 6902)
+	PatchTypeJSON = "JSON"
+)
+
+// VolumeTypes contains all LVM volume types which can be used in storage classes
+var VolumeTypes = []string{VolumeTypeLinear, VolumeTypeMirror, VolumeTypeStriped, VolumeTypeThin, VolumeTypeRaid1, VolumeTypeRaid5, VolumeTypeRaid10}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // ControllerConfiguration configuration resource
@@ -21,9 +87,294 @@ type CsiDriverLvmConfig struct {
 	// +optional
 	DevicePattern *string `json:"devicePattern,omitempty"`
 
-	// HostWritePath can be used to configure the host write path - used on read-only filesystems (Talos  OS "/var/etc/lvm")
+	// HostWritePath can be used to configure the host write path of all worker pools, by default it depends on the machine image (Talos OS "/var/etc/lvm")
 	// +optional
 	HostWritePath *string `json:"hostWritePath,omitempty"`
+
+	// StorageClasses can be used to customize the default storage classes or to add further storage classes, entries are matched by name
+	// +optional
+	StorageClasses []StorageClass `json:"storageClasses,omitempty"`
+
+	// LvmConfig can be used to configure the lvmlocal.conf of the plugin and the provisioner pods
+	// +optional
+	LvmConfig *LvmConfig `json:"lvmConfig,omitempty"`
+
+	// LoopDevices can be used to create file backed loop devices on the nodes, only supported for shoots with purpose development
+	// +optional
+	LoopDevices *LoopDevices `json:"loopDevices,omitempty"`
+
+	// Paths can be used to configure the host paths used by the driver, unset paths are defaulted by the operator
+	// +optional
+	Paths *Paths `json:"paths,omitempty"`
+
+	// HighAvailability runs the controller with multiple replicas and leader election, by default it is enabled if the
+	// control plane of the shoot is configured with a failure tolerance
+	// +optional
+	HighAvailability *bool `json:"highAvailability,omitempty"`
+
+	// PluginUpdateStrategy configures how the plugin daemon sets are updated, it defaults to a rolling update with one unavailable node
+	// +optional
+	PluginUpdateStrategy *PluginUpdateStrategy `json:"pluginUpdateStrategy,omitempty"`
+
+	// ReclaimPolicy is the reclaim policy of the storage classes, either "Delete" or "Retain", it defaults to the purpose profile of the operator or "Delete"
+	// +optional
+	ReclaimPolicy *corev1.PersistentVolumeReclaimPolicy `json:"reclaimPolicy,omitempty"`
+
+	// LogLevel is the log level of the csi sidecars, it defaults to the purpose profile of the operator or 5
+	// +optional
+	LogLevel *int32 `json:"logLevel,omitempty"`
+
+	// DefaultStorageClass is the name of the storage class marked as the default storage class of the shoot
+	// +optional
+	DefaultStorageClass *string `json:"defaultStorageClass,omitempty"`
+
+	// DeletionPolicy is the policy for the objects deployed into the shoot when the extension is removed, "Delete" removes them and "Retain" keeps them, it defaults to the purpose profile of the operator or "Delete"
+	// +optional
+	DeletionPolicy *string `json:"deletionPolicy,omitempty"`
+
+	// Patches can be used to patch the objects deployed into the shoot, the operator has to allow the kinds and fields of the patched objects
+	// +optional
+	Patches []Patch `json:"patches,omitempty"`
+}
+
+// Patch is a patch of an object deployed into the shoot
+type Patch struct {
+	// Kind is the kind of the patched object (e.g. "DaemonSet")
+	Kind string `json:"kind"`
+
+	// Name is the name of the patched object
+	Name string `json:"name"`
+
+	// Type is the type of the patch, either "StrategicMerge" or "JSON", it defaults to "StrategicMerge"
+	// +optional
+	Type *string `json:"type,omitempty"`
+
+	// Patch is the strategic merge patch or the JSON patch in JSON or YAML
+	Patch string `json:"patch"`
+}
+
+// PluginUpdateStrategy configures the update strategy of the plugin daemon sets
+type PluginUpdateStrategy struct {
+	// Type is the type of the update strategy, either "RollingUpdate" or "OnDelete" to restart the plugin only when the pods are deleted, e.g. during the node maintenance
+	// +optional
+	Type *appsv1.DaemonSetUpdateStrategyType `json:"type,omitempty"`
+
+	// MaxUnavailable is the maximum number of nodes or percentage of nodes without an available plugin during a rolling update
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MaxSurge is the maximum number of nodes or percentage of nodes running an updated plugin in addition to the old one during a rolling update
+	// +optional
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+}
+
+// Paths configures the host paths used by the driver on the nodes
+type Paths struct {
+	// KubeletRootDir is the root directory of the kubelet (e.g. "/var/lib/kubelet")
+	// +optional
+	KubeletRootDir *string `json:"kubeletRootDir,omitempty"`
+
+	// PluginDir is the directory of the driver socket, it defaults to the "plugins/csi-driver-lvm" directory below the kubelet root directory
+	// +optional
+	PluginDir *string `json:"pluginDir,omitempty"`
+
+	// RegistrationDir is the plugin registration directory of the kubelet, it defaults to the "plugins_registry" directory below the kubelet root directory
+	// +optional
+	RegistrationDir *string `json:"registrationDir,omitempty"`
+
+	// DevDir is the directory containing the device files (e.g. "/dev")
+	// +optional
+	DevDir *string `json:"devDir,omitempty"`
+
+	// ModulesDir is the directory containing the kernel modules (e.g. "/lib/modules")
+	// +optional
+	ModulesDir *string `json:"modulesDir,omitempty"`
+}
+
+// LoopDevices configures the file backed loop devices created on the nodes of development shoots
+type LoopDevices struct {
+	// Count is the number of loop devices created on every node
+	Count int32 `json:"count"`
+
+	// Size is the size of the backing file of every loop device
+	Size resource.Quantity `json:"size"`
+}
+
+// LvmConfig contains the supported settings of the lvmlocal.conf. The provisioner pods which create and remove the
+// logical volumes are started by the driver, they receive the lvmlocal.conf from a webhook in the shoot.
+type LvmConfig struct {
+	// DeviceFilter is the devices/filter setting, every entry accepts ("a|regex|") or rejects ("r|regex|") the matching devices
+	// +optional
+	DeviceFilter []string `json:"deviceFilter,omitempty"`
+
+	// IssueDiscards is the devices/issue_discards setting, discards are sent to the devices when logical volumes are removed
+	// +optional
+	IssueDiscards *bool `json:"issueDiscards,omitempty"`
+
+	// ThinPoolAutoextendThreshold is the activation/thin_pool_autoextend_threshold setting in percent, 100 disables the autoextension
+	// +optional
+	ThinPoolAutoextendThreshold *int32 `json:"thinPoolAutoextendThreshold,omitempty"`
+
+	// ThinPoolAutoextendPercent is the activation/thin_pool_autoextend_percent setting
+	// +optional
+	ThinPoolAutoextendPercent *int32 `json:"thinPoolAutoextendPercent,omitempty"`
+}
+
+// StorageClass configures a storage class deployed into the shoot
+type StorageClass struct {
+	// Name is the name of the storage class
+	Name string `json:"name"`
+
+	// Type is the LVM volume type of the storage class, required for storage classes which are not deployed by default
+	// +optional
+	Type *string `json:"type,omitempty"`
+
+	// FsType can be used to configure the filesystem type of the volumes (e.g. "xfs" or "ext4")
+	// +optional
+	FsType *string `json:"fsType,omitempty"`
+
+	// MountOptions can be used to configure the mount options of the volumes (e.g. "noatime" or "discard")
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty"`
+
+	// Stripes can be used to configure the number of stripes of striped volumes, the pool needs at least as many devices
+	// +optional
+	Stripes *int32 `json:"stripes,omitempty"`
+
+	// StripeSize can be used to configure the stripe size of striped volumes, must be a power of two and at least 4Ki
+	// +optional
+	StripeSize *resource.Quantity `json:"stripeSize,omitempty"`
+
+	// Mirrors can be used to configure the number of additional copies of mirrored volumes, the pool needs at least one device more
+	// +optional
+	Mirrors *int32 `json:"mirrors,omitempty"`
+
+	// ThinPool can be used to configure the thin pool of thin provisioned volumes
+	// +optional
+	ThinPool *ThinPool `json:"thinPool,omitempty"`
+
+	// Encryption can be used to encrypt the volumes with LUKS
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+}
+
+// ThinPool configures the thin pool backing thin provisioned volumes
+type ThinPool struct {
+	// SizePercentage is the size of the thin pool in percent of the volume group
+	// +optional
+	SizePercentage *int32 `json:"sizePercentage,omitempty"`
+
+	// OvercommitRatio is the maximum ratio of the provisioned volume sizes to the size of the thin pool
+	// +optional
+	OvercommitRatio *int32 `json:"overcommitRatio,omitempty"`
+}
+
+// Encryption configures the LUKS encryption of volumes
+type Encryption struct {
+	// SecretResourceName is the name of the shoot resource (spec.resources) referencing the secret which contains the LUKS passphrase in the key "passphrase"
+	SecretResourceName string `json:"secretResourceName"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CsiDriverLvmStatus contains information about the csi-driver-lvm deployed into the shoot
+type CsiDriverLvmStatus struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// EncryptionKeys contains the state of the encryption keys deployed for the storage classes
+	// +optional
+	EncryptionKeys []EncryptionKeyStatus `json:"encryptionKeys,omitempty"`
+
+	// WorkerPools contains the node specific settings resolved for the worker pools of the shoot
+	// +optional
+	WorkerPools []WorkerPoolStatus `json:"workerPools,omitempty"`
+
+	// PluginRollouts contains the rollout progress of the plugin daemon sets
+	// +optional
+	PluginRollouts []PluginRolloutStatus `json:"pluginRollouts,omitempty"`
+
+	// Images contains the images deployed into the shoot by their name in the image vector, changed images are held
+	// back until the maintenance window of the shoot
+	// +optional
+	Images map[string]string `json:"images,omitempty"`
+
+	// ImageSet is the image set deployed into the shoot, "canary" if the shoot is selected by the rollout policy of
+	// the operator, otherwise "stable"
+	// +optional
+	ImageSet string `json:"imageSet,omitempty"`
+
+	// OldCsiLvmAbsent is true once the absence of the old csi-lvm has been confirmed, the shoot is not probed again
+	// unless the extension is annotated with "csi-driver-lvm.metal.extensions.gardener.cloud/recheck-old-csi-lvm=true"
+	// +optional
+	OldCsiLvmAbsent bool `json:"oldCsiLvmAbsent,omitempty"`
+
+	// ManagedResources contains the health of the managed resources of the components deployed into the shoot
+	// +optional
+	ManagedResources []ManagedResourceStatus `json:"managedResources,omitempty"`
+}
+
+// ManagedResourceStatus contains the health of the managed resource of a component deployed into the shoot
+type ManagedResourceStatus struct {
+	// Name is the name of the managed resource
+	Name string `json:"name"`
+
+	// Healthy is true if the resources of the managed resource are applied and healthy
+	Healthy bool `json:"healthy"`
+
+	// Message describes why the managed resource is not healthy
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PluginRolloutStatus contains the rollout progress of a plugin daemon set
+type PluginRolloutStatus struct {
+	// Name is the name of the plugin daemon set
+	Name string `json:"name"`
+
+	// DesiredNodes is the number of nodes which should run the plugin
+	DesiredNodes int32 `json:"desiredNodes"`
+
+	// UpdatedNodes is the number of nodes running the updated plugin
+	UpdatedNodes int32 `json:"updatedNodes"`
+
+	// AvailableNodes is the number of nodes running an available plugin
+	AvailableNodes int32 `json:"availableNodes"`
+}
+
+// WorkerPoolStatus contains the node specific settings used for a worker pool
+type WorkerPoolStatus struct {
+	// Name is the name of the worker pool
+	Name string `json:"name"`
+
+	// MachineImage is the name of the machine image of the worker pool
+	// +optional
+	MachineImage string `json:"machineImage,omitempty"`
+
+	// HostWritePath is the host write path used on the nodes of the worker pool
+	HostWritePath string `json:"hostWritePath"`
+
+	// KubeletRootDir is the root directory of the kubelet on the nodes of the worker pool
+	KubeletRootDir string `json:"kubeletRootDir"`
+}
+
+// EncryptionKeyStatus tracks the rotation of the encryption key of a storage class, every key is deployed into its own
+// secret as the existing volumes keep referencing the key they were created with
+type EncryptionKeyStatus struct {
+	// StorageClass is the name of the storage class using the key
+	StorageClass string `json:"storageClass"`
+
+	// Checksum is the checksum of the current key deployed into the shoot keyed with a secret of the seed, it is empty
+	// if the storage class is not encrypted anymore
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// LastRotationTime is the time the current key has been changed the last time
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+
+	// PreviousChecksums contains the checksums of the previous keys, they are kept in the shoot as long as volumes use them
+	// +optional
+	PreviousChecksums []string `json:"previousChecksums,omitempty"`
 }
 
 func (config *CsiDriverLvmConfig) ConfigureDefaults(hostWritePath *string, devicePattern *string) {
@@ -59,5 +410,204 @@ func (config *CsiDriverLvmConfig) IsValid(log logr.Logger) bool {
 		return false
 	}
 
+	if config.LvmConfig != nil && !config.LvmConfig.isValid(log) {
+		return false
+	}
+
+	if config.LoopDevices != nil && !config.LoopDevices.isValid(log) {
+		return false
+	}
+
+	if config.Paths != nil && !config.Paths.isValid(log) {
+		return false
+	}
+
+	if config.PluginUpdateStrategy != nil && !config.PluginUpdateStrategy.isValid(log) {
+		return false
+	}
+
+	if config.ReclaimPolicy != nil && *config.ReclaimPolicy != corev1.PersistentVolumeReclaimDelete && *config.ReclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		log.Info("unsupported reclaimPolicy", "reclaimPolicy", *config.ReclaimPolicy)
+		return false
+	}
+
+	if config.LogLevel != nil && (*config.LogLevel < 0 || *config.LogLevel > 10) {
+		log.Info("logLevel must be between 0 and 10")
+		return false
+	}
+
+	if config.DeletionPolicy != nil && *config.DeletionPolicy != DeletionPolicyDelete && *config.DeletionPolicy != DeletionPolicyRetain {
+		log.Info("unsupported deletionPolicy", "deletionPolicy", *config.DeletionPolicy)
+		return false
+	}
+
+	for _, patch := range config.Patches {
+		if patch.Kind == "" || patch.Name == "" {
+			log.Info("patch kind and name must not be empty")
+			return false
+		}
+		if patch.Type != nil && *patch.Type != PatchTypeStrategicMerge && *patch.Type != PatchTypeJSON {
+			log.Info("unsupported patch type", "kind", patch.Kind, "name", patch.Name, "type", *patch.Type)
+			return false
+		}
+		if strings.TrimSpace(patch.Patch) == "" {
+			log.Info("patch is empty", "kind", patch.Kind, "name", patch.Name)
+			return false
+		}
+	}
+
+	names := map[string]bool{}
+	for _, sc := range config.StorageClasses {
+		if errs := validation.IsDNS1123Subdomain(sc.Name); len(errs) > 0 {
+			log.Info("storage class name is invalid", "name", sc.Name, "errors", errs)
+			return false
+		}
+		if names[sc.Name] {
+			log.Info("storage class is configured more than once", "name", sc.Name)
+			return false
+		}
+		names[sc.Name] = true
+
+		if sc.Type != nil && !slices.Contains(VolumeTypes, *sc.Type) {
+			log.Info("storage class type is not supported", "name", sc.Name, "type", *sc.Type)
+			return false
+		}
+		if sc.FsType != nil && *sc.FsType == "" {
+			log.Info("storage class fsType is empty", "name", sc.Name)
+			return false
+		}
+		if slices.Contains(sc.MountOptions, "") {
+			log.Info("storage class contains an empty mount option", "name", sc.Name)
+			return false
+		}
+		if sc.Stripes != nil && *sc.Stripes < 2 {
+			log.Info("storage class stripes must be at least 2", "name", sc.Name)
+			return false
+		}
+		if sc.StripeSize != nil && !isValidStripeSize(*sc.StripeSize) {
+			log.Info("storage class stripeSize must be a power of two and at least 4Ki", "name", sc.Name)
+			return false
+		}
+		if sc.Mirrors != nil && *sc.Mirrors < 1 {
+			log.Info("storage class mirrors must be at least 1", "name", sc.Name)
+			return false
+		}
+		if sc.ThinPool != nil && sc.ThinPool.SizePercentage != nil && (*sc.ThinPool.SizePercentage < 1 || *sc.ThinPool.SizePercentage > 100) {
+			log.Info("storage class thin pool sizePercentage must be between 1 and 100", "name", sc.Name)
+			return false
+		}
+		if sc.ThinPool != nil && sc.ThinPool.OvercommitRatio != nil && *sc.ThinPool.OvercommitRatio < 1 {
+			log.Info("storage class thin pool overcommitRatio must be at least 1", "name", sc.Name)
+			return false
+		}
+		if sc.Encryption != nil && sc.Encryption.SecretResourceName == "" {
+			log.Info("storage class encryption secretResourceName is empty", "name", sc.Name)
+			return false
+		}
+	}
+
 	return true
 }
+
+func isValidStripeSize(stripeSize resource.Quantity) bool {
+	size := stripeSize.Value()
+	return size >= 4*1024 && size&(size-1) == 0
+}
+
+func (lvmConfig *LvmConfig) isValid(log logr.Logger) bool {
+	for _, filter := range lvmConfig.DeviceFilter {
+		if len(filter) < 4 || (filter[0] != 'a' && filter[0] != 'r') || filter[1] != '|' || filter[len(filter)-1] != '|' {
+			log.Info("lvm device filter must have the form \"a|regex|\" or \"r|regex|\"", "filter", filter)
+			return false
+		}
+		expression := filter[2 : len(filter)-1]
+		if strings.ContainsAny(expression, "|\"") {
+			log.Info("lvm device filter must not contain further separators or quotes", "filter", filter)
+			return false
+		}
+		if _, err := regexp.Compile(expression); err != nil {
+			log.Info("lvm device filter contains an invalid regular expression", "filter", filter)
+			return false
+		}
+	}
+
+	if lvmConfig.ThinPoolAutoextendThreshold != nil && (*lvmConfig.ThinPoolAutoextendThreshold < 50 || *lvmConfig.ThinPoolAutoextendThreshold > 100) {
+		log.Info("lvm thinPoolAutoextendThreshold must be between 50 and 100")
+		return false
+	}
+
+	if lvmConfig.ThinPoolAutoextendPercent != nil && *lvmConfig.ThinPoolAutoextendPercent < 1 {
+		log.Info("lvm thinPoolAutoextendPercent must be at least 1")
+		return false
+	}
+
+	return true
+}
+
+func (loopDevices *LoopDevices) isValid(log logr.Logger) bool {
+	if loopDevices.Count < 1 {
+		log.Info("loopDevices count must be at least 1")
+		return false
+	}
+
+	if loopDevices.Size.Sign() <= 0 {
+		log.Info("loopDevices size must be positive")
+		return false
+	}
+
+	return true
+}
+
+func (paths *Paths) isValid(log logr.Logger) bool {
+	for name, path := range map[string]*string{
+		"kubeletRootDir":  paths.KubeletRootDir,
+		"pluginDir":       paths.PluginDir,
+		"registrationDir": paths.RegistrationDir,
+		"devDir":          paths.DevDir,
+		"modulesDir":      paths.ModulesDir,
+	} {
+		if path != nil && !filepath.IsAbs(*path) {
+			log.Info("paths must be absolute", "path", name)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (strategy *PluginUpdateStrategy) isValid(log logr.Logger) bool {
+	if strategy.Type != nil && *strategy.Type != appsv1.RollingUpdateDaemonSetStrategyType && *strategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+		log.Info("unsupported pluginUpdateStrategy type", "type", *strategy.Type)
+		return false
+	}
+
+	if strategy.Type != nil && *strategy.Type == appsv1.OnDeleteDaemonSetStrategyType && (strategy.MaxUnavailable != nil || strategy.MaxSurge != nil) {
+		log.Info("pluginUpdateStrategy maxUnavailable and maxSurge are only supported for rolling updates")
+		return false
+	}
+
+	// unset fields take the defaults of the daemonset rolling update, a maxUnavailable of 1 and a maxSurge of 0
+	maxUnavailable, ok := scaledPercentage(ptr.Deref(strategy.MaxUnavailable, intstr.FromInt32(1)))
+	if !ok {
+		log.Info("pluginUpdateStrategy maxUnavailable is invalid")
+		return false
+	}
+	maxSurge, ok := scaledPercentage(ptr.Deref(strategy.MaxSurge, intstr.FromInt32(0)))
+	if !ok {
+		log.Info("pluginUpdateStrategy maxSurge is invalid")
+		return false
+	}
+
+	if maxUnavailable == 0 && maxSurge == 0 {
+		log.Info("pluginUpdateStrategy maxUnavailable and maxSurge must not both be zero")
+		return false
+	}
+
+	return true
+}
+
+// scaledPercentage returns the value of the number or percentage scaled to 100, it is valid if not negative
+func scaledPercentage(value intstr.IntOrString) (int, bool) {
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(&value, 100, true)
+	return scaled, err == nil && scaled >= 0
+}
@@ -5,6 +5,10 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
@@ -97,6 +101,427 @@ func TestConfig(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			desc: "test valid storage classes config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-linear", FsType: ptr.To("xfs"), MountOptions: []string{"noatime"}},
+					{Name: "csi-driver-lvm-striped-ext4", Type: ptr.To("striped"), FsType: ptr.To("ext4")},
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test invalid storage class name config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "Invalid_Name", Type: ptr.To("linear")},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test duplicate storage class config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-linear", FsType: ptr.To("xfs")},
+					{Name: "csi-driver-lvm-linear", FsType: ptr.To("ext4")},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test unknown storage class type config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-foo", Type: ptr.To("foo")},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test empty storage class fsType config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-linear", FsType: ptr.To("")},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test empty storage class mount option config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-linear", MountOptions: []string{""}},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test valid geometry config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-striped", Stripes: ptr.To(int32(4)), StripeSize: ptr.To(resource.MustParse("64Ki"))},
+					{Name: "csi-driver-lvm-mirror", Mirrors: ptr.To(int32(2))},
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test single stripe config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-striped", Stripes: ptr.To(int32(1))},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test stripe size not a power of two config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-striped", StripeSize: ptr.To(resource.MustParse("48Ki"))},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test stripe size too small config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-striped", StripeSize: ptr.To(resource.MustParse("2Ki"))},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test zero mirrors config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-mirror", Mirrors: ptr.To(int32(0))},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test valid thin pool config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-thin", Type: ptr.To("thin"), ThinPool: &ThinPool{SizePercentage: ptr.To(int32(90)), OvercommitRatio: ptr.To(int32(3))}},
+					{Name: "csi-driver-lvm-raid10", Type: ptr.To("raid10")},
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test thin pool size percentage out of range config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-thin", Type: ptr.To("thin"), ThinPool: &ThinPool{SizePercentage: ptr.To(int32(101))}},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test thin pool overcommit ratio too small config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				StorageClasses: []StorageClass{
+					{Name: "csi-driver-lvm-thin", Type: ptr.To("thin"), ThinPool: &ThinPool{OvercommitRatio: ptr.To(int32(0))}},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test valid lvm config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LvmConfig: &LvmConfig{
+					DeviceFilter:                []string{"a|^/dev/nvme[0-9]n1$|", "r|.*|"},
+					IssueDiscards:               ptr.To(true),
+					ThinPoolAutoextendThreshold: ptr.To(int32(80)),
+					ThinPoolAutoextendPercent:   ptr.To(int32(20)),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test lvm device filter without action config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LvmConfig: &LvmConfig{
+					DeviceFilter: []string{"|^/dev/nvme.*|"},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test lvm device filter with quotes config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LvmConfig: &LvmConfig{
+					DeviceFilter: []string{"a|.*\" ]\n|"},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test lvm device filter with invalid regex config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LvmConfig: &LvmConfig{
+					DeviceFilter: []string{"a|^/dev/nvme[0-9|"},
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test lvm thin pool autoextend threshold too low config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop10[0,1]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LvmConfig: &LvmConfig{
+					ThinPoolAutoextendThreshold: ptr.To(int32(40)),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test loop devices config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop*"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LoopDevices: &LoopDevices{
+					Count: 2,
+					Size:  resource.MustParse("10Gi"),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test loop devices without size config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/loop*"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LoopDevices: &LoopDevices{
+					Count: 2,
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test paths config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Paths: &Paths{
+					KubeletRootDir: ptr.To("/var/data/kubelet"),
+					ModulesDir:     ptr.To("/usr/lib/modules"),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test relative paths config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Paths: &Paths{
+					RegistrationDir: ptr.To("var/lib/kubelet/plugins_registry"),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test rolling update plugin update strategy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					Type:           ptr.To(appsv1.RollingUpdateDaemonSetStrategyType),
+					MaxUnavailable: ptr.To(intstr.FromString("20%")),
+					MaxSurge:       ptr.To(intstr.FromInt32(0)),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test on delete plugin update strategy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					Type: ptr.To(appsv1.OnDeleteDaemonSetStrategyType),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test on delete plugin update strategy with max unavailable config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					Type:           ptr.To(appsv1.OnDeleteDaemonSetStrategyType),
+					MaxUnavailable: ptr.To(intstr.FromInt32(2)),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test zero max unavailable and max surge plugin update strategy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+					MaxSurge:       ptr.To(intstr.FromString("0%")),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test zero max unavailable with default max surge plugin update strategy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test zero max unavailable with max surge plugin update strategy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+					MaxSurge:       ptr.To(intstr.FromInt32(1)),
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test retain policies config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern:  ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath:  ptr.To("/etc/lvm"),
+				ReclaimPolicy:  ptr.To(corev1.PersistentVolumeReclaimRetain),
+				LogLevel:       ptr.To(int32(2)),
+				DeletionPolicy: ptr.To(DeletionPolicyRetain),
+			},
+			valid: true,
+		},
+		{
+			desc: "test recycle reclaim policy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				ReclaimPolicy: ptr.To(corev1.PersistentVolumeReclaimRecycle),
+			},
+			valid: false,
+		},
+		{
+			desc: "test log level too high config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				LogLevel:      ptr.To(int32(11)),
+			},
+			valid: false,
+		},
+		{
+			desc: "test unknown deletion policy config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern:  ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath:  ptr.To("/etc/lvm"),
+				DeletionPolicy: ptr.To("Orphan"),
+			},
+			valid: false,
+		},
+		{
+			desc: "test unknown plugin update strategy type config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				PluginUpdateStrategy: &PluginUpdateStrategy{
+					Type: ptr.To(appsv1.DaemonSetUpdateStrategyType("Recreate")),
+				},
+			},
+			valid: false,
+		},
+		{
+			desc: "test patches config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Patches: []Patch{
+					{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: "metadata:\n  annotations:\n    team: storage\n"},
+					{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Type: ptr.To(PatchTypeJSON), Patch: `[{"op": "add", "path": "/metadata/labels/team", "value": "storage"}]`},
+				},
+			},
+			valid: true,
+		},
+		{
+			desc: "test patch without name config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Patches:       []Patch{{Kind: "DaemonSet", Patch: "metadata: {}"}},
+			},
+			valid: false,
+		},
+		{
+			desc: "test unknown patch type config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Patches:       []Patch{{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Type: ptr.To("Merge"), Patch: "metadata: {}"}},
+			},
+			valid: false,
+		},
+		{
+			desc: "test empty patch config",
+			customData: &CsiDriverLvmConfig{
+				DevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"),
+				HostWritePath: ptr.To("/etc/lvm"),
+				Patches:       []Patch{{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin"}},
+			},
+			valid: false,
+		},
 	}
 
 	for _, tc := range tt {
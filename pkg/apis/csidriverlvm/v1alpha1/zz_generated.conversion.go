@@ -13,8 +13,13 @@ import (
 	unsafe "unsafe"
 
 	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func init() {
@@ -34,12 +39,153 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CsiDriverLvmStatus)(nil), (*csidriverlvm.CsiDriverLvmStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CsiDriverLvmStatus_To_csidriverlvm_CsiDriverLvmStatus(a.(*CsiDriverLvmStatus), b.(*csidriverlvm.CsiDriverLvmStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.CsiDriverLvmStatus)(nil), (*CsiDriverLvmStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_CsiDriverLvmStatus_To_v1alpha1_CsiDriverLvmStatus(a.(*csidriverlvm.CsiDriverLvmStatus), b.(*CsiDriverLvmStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Encryption)(nil), (*csidriverlvm.Encryption)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Encryption_To_csidriverlvm_Encryption(a.(*Encryption), b.(*csidriverlvm.Encryption), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.Encryption)(nil), (*Encryption)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_Encryption_To_v1alpha1_Encryption(a.(*csidriverlvm.Encryption), b.(*Encryption), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*EncryptionKeyStatus)(nil), (*csidriverlvm.EncryptionKeyStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_EncryptionKeyStatus_To_csidriverlvm_EncryptionKeyStatus(a.(*EncryptionKeyStatus), b.(*csidriverlvm.EncryptionKeyStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.EncryptionKeyStatus)(nil), (*EncryptionKeyStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_EncryptionKeyStatus_To_v1alpha1_EncryptionKeyStatus(a.(*csidriverlvm.EncryptionKeyStatus), b.(*EncryptionKeyStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*LoopDevices)(nil), (*csidriverlvm.LoopDevices)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_LoopDevices_To_csidriverlvm_LoopDevices(a.(*LoopDevices), b.(*csidriverlvm.LoopDevices), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.LoopDevices)(nil), (*LoopDevices)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_LoopDevices_To_v1alpha1_LoopDevices(a.(*csidriverlvm.LoopDevices), b.(*LoopDevices), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*LvmConfig)(nil), (*csidriverlvm.LvmConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_LvmConfig_To_csidriverlvm_LvmConfig(a.(*LvmConfig), b.(*csidriverlvm.LvmConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.LvmConfig)(nil), (*LvmConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_LvmConfig_To_v1alpha1_LvmConfig(a.(*csidriverlvm.LvmConfig), b.(*LvmConfig), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ManagedResourceStatus)(nil), (*csidriverlvm.ManagedResourceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ManagedResourceStatus_To_csidriverlvm_ManagedResourceStatus(a.(*ManagedResourceStatus), b.(*csidriverlvm.ManagedResourceStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.ManagedResourceStatus)(nil), (*ManagedResourceStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_ManagedResourceStatus_To_v1alpha1_ManagedResourceStatus(a.(*csidriverlvm.ManagedResourceStatus), b.(*ManagedResourceStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Patch)(nil), (*csidriverlvm.Patch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Patch_To_csidriverlvm_Patch(a.(*Patch), b.(*csidriverlvm.Patch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.Patch)(nil), (*Patch)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_Patch_To_v1alpha1_Patch(a.(*csidriverlvm.Patch), b.(*Patch), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Paths)(nil), (*csidriverlvm.Paths)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Paths_To_csidriverlvm_Paths(a.(*Paths), b.(*csidriverlvm.Paths), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.Paths)(nil), (*Paths)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_Paths_To_v1alpha1_Paths(a.(*csidriverlvm.Paths), b.(*Paths), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PluginRolloutStatus)(nil), (*csidriverlvm.PluginRolloutStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PluginRolloutStatus_To_csidriverlvm_PluginRolloutStatus(a.(*PluginRolloutStatus), b.(*csidriverlvm.PluginRolloutStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.PluginRolloutStatus)(nil), (*PluginRolloutStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_PluginRolloutStatus_To_v1alpha1_PluginRolloutStatus(a.(*csidriverlvm.PluginRolloutStatus), b.(*PluginRolloutStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*PluginUpdateStrategy)(nil), (*csidriverlvm.PluginUpdateStrategy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_PluginUpdateStrategy_To_csidriverlvm_PluginUpdateStrategy(a.(*PluginUpdateStrategy), b.(*csidriverlvm.PluginUpdateStrategy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.PluginUpdateStrategy)(nil), (*PluginUpdateStrategy)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_PluginUpdateStrategy_To_v1alpha1_PluginUpdateStrategy(a.(*csidriverlvm.PluginUpdateStrategy), b.(*PluginUpdateStrategy), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StorageClass)(nil), (*csidriverlvm.StorageClass)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageClass_To_csidriverlvm_StorageClass(a.(*StorageClass), b.(*csidriverlvm.StorageClass), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.StorageClass)(nil), (*StorageClass)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_StorageClass_To_v1alpha1_StorageClass(a.(*csidriverlvm.StorageClass), b.(*StorageClass), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ThinPool)(nil), (*csidriverlvm.ThinPool)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ThinPool_To_csidriverlvm_ThinPool(a.(*ThinPool), b.(*csidriverlvm.ThinPool), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.ThinPool)(nil), (*ThinPool)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_ThinPool_To_v1alpha1_ThinPool(a.(*csidriverlvm.ThinPool), b.(*ThinPool), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*WorkerPoolStatus)(nil), (*csidriverlvm.WorkerPoolStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerPoolStatus_To_csidriverlvm_WorkerPoolStatus(a.(*WorkerPoolStatus), b.(*csidriverlvm.WorkerPoolStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*csidriverlvm.WorkerPoolStatus)(nil), (*WorkerPoolStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_csidriverlvm_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(a.(*csidriverlvm.WorkerPoolStatus), b.(*WorkerPoolStatus), scope)
+	}); err != nil {
+		return err
+	}
 	return nil
 }
 
 func autoConvert_v1alpha1_CsiDriverLvmConfig_To_csidriverlvm_CsiDriverLvmConfig(in *CsiDriverLvmConfig, out *csidriverlvm.CsiDriverLvmConfig, s conversion.Scope) error {
 	out.DevicePattern = (*string)(unsafe.Pointer(in.DevicePattern))
 	out.HostWritePath = (*string)(unsafe.Pointer(in.HostWritePath))
+	out.StorageClasses = *(*[]csidriverlvm.StorageClass)(unsafe.Pointer(&in.StorageClasses))
+	out.LvmConfig = (*csidriverlvm.LvmConfig)(unsafe.Pointer(in.LvmConfig))
+	out.LoopDevices = (*csidriverlvm.LoopDevices)(unsafe.Pointer(in.LoopDevices))
+	out.Paths = (*csidriverlvm.Paths)(unsafe.Pointer(in.Paths))
+	out.HighAvailability = (*bool)(unsafe.Pointer(in.HighAvailability))
+	out.PluginUpdateStrategy = (*csidriverlvm.PluginUpdateStrategy)(unsafe.Pointer(in.PluginUpdateStrategy))
+	out.ReclaimPolicy = (*v1.PersistentVolumeReclaimPolicy)(unsafe.Pointer(in.ReclaimPolicy))
+	out.LogLevel = (*int32)(unsafe.Pointer(in.LogLevel))
+	out.DefaultStorageClass = (*string)(unsafe.Pointer(in.DefaultStorageClass))
+	out.DeletionPolicy = (*string)(unsafe.Pointer(in.DeletionPolicy))
+	out.Patches = *(*[]csidriverlvm.Patch)(unsafe.Pointer(&in.Patches))
 	return nil
 }
 
@@ -51,6 +197,17 @@ func Convert_v1alpha1_CsiDriverLvmConfig_To_csidriverlvm_CsiDriverLvmConfig(in *
 func autoConvert_csidriverlvm_CsiDriverLvmConfig_To_v1alpha1_CsiDriverLvmConfig(in *csidriverlvm.CsiDriverLvmConfig, out *CsiDriverLvmConfig, s conversion.Scope) error {
 	out.DevicePattern = (*string)(unsafe.Pointer(in.DevicePattern))
 	out.HostWritePath = (*string)(unsafe.Pointer(in.HostWritePath))
+	out.StorageClasses = *(*[]StorageClass)(unsafe.Pointer(&in.StorageClasses))
+	out.LvmConfig = (*LvmConfig)(unsafe.Pointer(in.LvmConfig))
+	out.LoopDevices = (*LoopDevices)(unsafe.Pointer(in.LoopDevices))
+	out.Paths = (*Paths)(unsafe.Pointer(in.Paths))
+	out.HighAvailability = (*bool)(unsafe.Pointer(in.HighAvailability))
+	out.PluginUpdateStrategy = (*PluginUpdateStrategy)(unsafe.Pointer(in.PluginUpdateStrategy))
+	out.ReclaimPolicy = (*v1.PersistentVolumeReclaimPolicy)(unsafe.Pointer(in.ReclaimPolicy))
+	out.LogLevel = (*int32)(unsafe.Pointer(in.LogLevel))
+	out.DefaultStorageClass = (*string)(unsafe.Pointer(in.DefaultStorageClass))
+	out.DeletionPolicy = (*string)(unsafe.Pointer(in.DeletionPolicy))
+	out.Patches = *(*[]Patch)(unsafe.Pointer(&in.Patches))
 	return nil
 }
 
@@ -58,3 +215,341 @@ func autoConvert_csidriverlvm_CsiDriverLvmConfig_To_v1alpha1_CsiDriverLvmConfig(
 func Convert_csidriverlvm_CsiDriverLvmConfig_To_v1alpha1_CsiDriverLvmConfig(in *csidriverlvm.CsiDriverLvmConfig, out *CsiDriverLvmConfig, s conversion.Scope) error {
 	return autoConvert_csidriverlvm_CsiDriverLvmConfig_To_v1alpha1_CsiDriverLvmConfig(in, out, s)
 }
+
+func autoConvert_v1alpha1_CsiDriverLvmStatus_To_csidriverlvm_CsiDriverLvmStatus(in *CsiDriverLvmStatus, out *csidriverlvm.CsiDriverLvmStatus, s conversion.Scope) error {
+	out.EncryptionKeys = *(*[]csidriverlvm.EncryptionKeyStatus)(unsafe.Pointer(&in.EncryptionKeys))
+	out.WorkerPools = *(*[]csidriverlvm.WorkerPoolStatus)(unsafe.Pointer(&in.WorkerPools))
+	out.PluginRollouts = *(*[]csidriverlvm.PluginRolloutStatus)(unsafe.Pointer(&in.PluginRollouts))
+	out.Images = *(*map[string]string)(unsafe.Pointer(&in.Images))
+	out.ImageSet = in.ImageSet
+	out.OldCsiLvmAbsent = in.OldCsiLvmAbsent
+	out.ManagedResources = *(*[]csidriverlvm.ManagedResourceStatus)(unsafe.Pointer(&in.ManagedResources))
+	return nil
+}
+
+// Convert_v1alpha1_CsiDriverLvmStatus_To_csidriverlvm_CsiDriverLvmStatus is an autogenerated conversion function.
+func Convert_v1alpha1_CsiDriverLvmStatus_To_csidriverlvm_CsiDriverLvmStatus(in *CsiDriverLvmStatus, out *csidriverlvm.CsiDriverLvmStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CsiDriverLvmStatus_To_csidriverlvm_CsiDriverLvmStatus(in, out, s)
+}
+
+func autoConvert_csidriverlvm_CsiDriverLvmStatus_To_v1alpha1_CsiDriverLvmStatus(in *csidriverlvm.CsiDriverLvmStatus, out *CsiDriverLvmStatus, s conversion.Scope) error {
+	out.EncryptionKeys = *(*[]EncryptionKeyStatus)(unsafe.Pointer(&in.EncryptionKeys))
+	out.WorkerPools = *(*[]WorkerPoolStatus)(unsafe.Pointer(&in.WorkerPools))
+	out.PluginRollouts = *(*[]PluginRolloutStatus)(unsafe.Pointer(&in.PluginRollouts))
+	out.Images = *(*map[string]string)(unsafe.Pointer(&in.Images))
+	out.ImageSet = in.ImageSet
+	out.OldCsiLvmAbsent = in.OldCsiLvmAbsent
+	out.ManagedResources = *(*[]ManagedResourceStatus)(unsafe.Pointer(&in.ManagedResources))
+	return nil
+}
+
+// Convert_csidriverlvm_CsiDriverLvmStatus_To_v1alpha1_CsiDriverLvmStatus is an autogenerated conversion function.
+func Convert_csidriverlvm_CsiDriverLvmStatus_To_v1alpha1_CsiDriverLvmStatus(in *csidriverlvm.CsiDriverLvmStatus, out *CsiDriverLvmStatus, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_CsiDriverLvmStatus_To_v1alpha1_CsiDriverLvmStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_Encryption_To_csidriverlvm_Encryption(in *Encryption, out *csidriverlvm.Encryption, s conversion.Scope) error {
+	out.SecretResourceName = in.SecretResourceName
+	return nil
+}
+
+// Convert_v1alpha1_Encryption_To_csidriverlvm_Encryption is an autogenerated conversion function.
+func Convert_v1alpha1_Encryption_To_csidriverlvm_Encryption(in *Encryption, out *csidriverlvm.Encryption, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Encryption_To_csidriverlvm_Encryption(in, out, s)
+}
+
+func autoConvert_csidriverlvm_Encryption_To_v1alpha1_Encryption(in *csidriverlvm.Encryption, out *Encryption, s conversion.Scope) error {
+	out.SecretResourceName = in.SecretResourceName
+	return nil
+}
+
+// Convert_csidriverlvm_Encryption_To_v1alpha1_Encryption is an autogenerated conversion function.
+func Convert_csidriverlvm_Encryption_To_v1alpha1_Encryption(in *csidriverlvm.Encryption, out *Encryption, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_Encryption_To_v1alpha1_Encryption(in, out, s)
+}
+
+func autoConvert_v1alpha1_EncryptionKeyStatus_To_csidriverlvm_EncryptionKeyStatus(in *EncryptionKeyStatus, out *csidriverlvm.EncryptionKeyStatus, s conversion.Scope) error {
+	out.StorageClass = in.StorageClass
+	out.Checksum = in.Checksum
+	out.LastRotationTime = (*metav1.Time)(unsafe.Pointer(in.LastRotationTime))
+	out.PreviousChecksums = *(*[]string)(unsafe.Pointer(&in.PreviousChecksums))
+	return nil
+}
+
+// Convert_v1alpha1_EncryptionKeyStatus_To_csidriverlvm_EncryptionKeyStatus is an autogenerated conversion function.
+func Convert_v1alpha1_EncryptionKeyStatus_To_csidriverlvm_EncryptionKeyStatus(in *EncryptionKeyStatus, out *csidriverlvm.EncryptionKeyStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_EncryptionKeyStatus_To_csidriverlvm_EncryptionKeyStatus(in, out, s)
+}
+
+func autoConvert_csidriverlvm_EncryptionKeyStatus_To_v1alpha1_EncryptionKeyStatus(in *csidriverlvm.EncryptionKeyStatus, out *EncryptionKeyStatus, s conversion.Scope) error {
+	out.StorageClass = in.StorageClass
+	out.Checksum = in.Checksum
+	out.LastRotationTime = (*metav1.Time)(unsafe.Pointer(in.LastRotationTime))
+	out.PreviousChecksums = *(*[]string)(unsafe.Pointer(&in.PreviousChecksums))
+	return nil
+}
+
+// Convert_csidriverlvm_EncryptionKeyStatus_To_v1alpha1_EncryptionKeyStatus is an autogenerated conversion function.
+func Convert_csidriverlvm_EncryptionKeyStatus_To_v1alpha1_EncryptionKeyStatus(in *csidriverlvm.EncryptionKeyStatus, out *EncryptionKeyStatus, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_EncryptionKeyStatus_To_v1alpha1_EncryptionKeyStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_LoopDevices_To_csidriverlvm_LoopDevices(in *LoopDevices, out *csidriverlvm.LoopDevices, s conversion.Scope) error {
+	out.Count = in.Count
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_v1alpha1_LoopDevices_To_csidriverlvm_LoopDevices is an autogenerated conversion function.
+func Convert_v1alpha1_LoopDevices_To_csidriverlvm_LoopDevices(in *LoopDevices, out *csidriverlvm.LoopDevices, s conversion.Scope) error {
+	return autoConvert_v1alpha1_LoopDevices_To_csidriverlvm_LoopDevices(in, out, s)
+}
+
+func autoConvert_csidriverlvm_LoopDevices_To_v1alpha1_LoopDevices(in *csidriverlvm.LoopDevices, out *LoopDevices, s conversion.Scope) error {
+	out.Count = in.Count
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_csidriverlvm_LoopDevices_To_v1alpha1_LoopDevices is an autogenerated conversion function.
+func Convert_csidriverlvm_LoopDevices_To_v1alpha1_LoopDevices(in *csidriverlvm.LoopDevices, out *LoopDevices, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_LoopDevices_To_v1alpha1_LoopDevices(in, out, s)
+}
+
+func autoConvert_v1alpha1_LvmConfig_To_csidriverlvm_LvmConfig(in *LvmConfig, out *csidriverlvm.LvmConfig, s conversion.Scope) error {
+	out.DeviceFilter = *(*[]string)(unsafe.Pointer(&in.DeviceFilter))
+	out.IssueDiscards = (*bool)(unsafe.Pointer(in.IssueDiscards))
+	out.ThinPoolAutoextendThreshold = (*int32)(unsafe.Pointer(in.ThinPoolAutoextendThreshold))
+	out.ThinPoolAutoextendPercent = (*int32)(unsafe.Pointer(in.ThinPoolAutoextendPercent))
+	return nil
+}
+
+// Convert_v1alpha1_LvmConfig_To_csidriverlvm_LvmConfig is an autogenerated conversion function.
+func Convert_v1alpha1_LvmConfig_To_csidriverlvm_LvmConfig(in *LvmConfig, out *csidriverlvm.LvmConfig, s conversion.Scope) error {
+	return autoConvert_v1alpha1_LvmConfig_To_csidriverlvm_LvmConfig(in, out, s)
+}
+
+func autoConvert_csidriverlvm_LvmConfig_To_v1alpha1_LvmConfig(in *csidriverlvm.LvmConfig, out *LvmConfig, s conversion.Scope) error {
+	out.DeviceFilter = *(*[]string)(unsafe.Pointer(&in.DeviceFilter))
+	out.IssueDiscards = (*bool)(unsafe.Pointer(in.IssueDiscards))
+	out.ThinPoolAutoextendThreshold = (*int32)(unsafe.Pointer(in.ThinPoolAutoextendThreshold))
+	out.ThinPoolAutoextendPercent = (*int32)(unsafe.Pointer(in.ThinPoolAutoextendPercent))
+	return nil
+}
+
+// Convert_csidriverlvm_LvmConfig_To_v1alpha1_LvmConfig is an autogenerated conversion function.
+func Convert_csidriverlvm_LvmConfig_To_v1alpha1_LvmConfig(in *csidriverlvm.LvmConfig, out *LvmConfig, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_LvmConfig_To_v1alpha1_LvmConfig(in, out, s)
+}
+
+func autoConvert_v1alpha1_ManagedResourceStatus_To_csidriverlvm_ManagedResourceStatus(in *ManagedResourceStatus, out *csidriverlvm.ManagedResourceStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Healthy = in.Healthy
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1alpha1_ManagedResourceStatus_To_csidriverlvm_ManagedResourceStatus is an autogenerated conversion function.
+func Convert_v1alpha1_ManagedResourceStatus_To_csidriverlvm_ManagedResourceStatus(in *ManagedResourceStatus, out *csidriverlvm.ManagedResourceStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ManagedResourceStatus_To_csidriverlvm_ManagedResourceStatus(in, out, s)
+}
+
+func autoConvert_csidriverlvm_ManagedResourceStatus_To_v1alpha1_ManagedResourceStatus(in *csidriverlvm.ManagedResourceStatus, out *ManagedResourceStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Healthy = in.Healthy
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_csidriverlvm_ManagedResourceStatus_To_v1alpha1_ManagedResourceStatus is an autogenerated conversion function.
+func Convert_csidriverlvm_ManagedResourceStatus_To_v1alpha1_ManagedResourceStatus(in *csidriverlvm.ManagedResourceStatus, out *ManagedResourceStatus, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_ManagedResourceStatus_To_v1alpha1_ManagedResourceStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_Patch_To_csidriverlvm_Patch(in *Patch, out *csidriverlvm.Patch, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.Patch = in.Patch
+	return nil
+}
+
+// Convert_v1alpha1_Patch_To_csidriverlvm_Patch is an autogenerated conversion function.
+func Convert_v1alpha1_Patch_To_csidriverlvm_Patch(in *Patch, out *csidriverlvm.Patch, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Patch_To_csidriverlvm_Patch(in, out, s)
+}
+
+func autoConvert_csidriverlvm_Patch_To_v1alpha1_Patch(in *csidriverlvm.Patch, out *Patch, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.Patch = in.Patch
+	return nil
+}
+
+// Convert_csidriverlvm_Patch_To_v1alpha1_Patch is an autogenerated conversion function.
+func Convert_csidriverlvm_Patch_To_v1alpha1_Patch(in *csidriverlvm.Patch, out *Patch, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_Patch_To_v1alpha1_Patch(in, out, s)
+}
+
+func autoConvert_v1alpha1_Paths_To_csidriverlvm_Paths(in *Paths, out *csidriverlvm.Paths, s conversion.Scope) error {
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	out.PluginDir = (*string)(unsafe.Pointer(in.PluginDir))
+	out.RegistrationDir = (*string)(unsafe.Pointer(in.RegistrationDir))
+	out.DevDir = (*string)(unsafe.Pointer(in.DevDir))
+	out.ModulesDir = (*string)(unsafe.Pointer(in.ModulesDir))
+	return nil
+}
+
+// Convert_v1alpha1_Paths_To_csidriverlvm_Paths is an autogenerated conversion function.
+func Convert_v1alpha1_Paths_To_csidriverlvm_Paths(in *Paths, out *csidriverlvm.Paths, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Paths_To_csidriverlvm_Paths(in, out, s)
+}
+
+func autoConvert_csidriverlvm_Paths_To_v1alpha1_Paths(in *csidriverlvm.Paths, out *Paths, s conversion.Scope) error {
+	out.KubeletRootDir = (*string)(unsafe.Pointer(in.KubeletRootDir))
+	out.PluginDir = (*string)(unsafe.Pointer(in.PluginDir))
+	out.RegistrationDir = (*string)(unsafe.Pointer(in.RegistrationDir))
+	out.DevDir = (*string)(unsafe.Pointer(in.DevDir))
+	out.ModulesDir = (*string)(unsafe.Pointer(in.ModulesDir))
+	return nil
+}
+
+// Convert_csidriverlvm_Paths_To_v1alpha1_Paths is an autogenerated conversion function.
+func Convert_csidriverlvm_Paths_To_v1alpha1_Paths(in *csidriverlvm.Paths, out *Paths, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_Paths_To_v1alpha1_Paths(in, out, s)
+}
+
+func autoConvert_v1alpha1_PluginRolloutStatus_To_csidriverlvm_PluginRolloutStatus(in *PluginRolloutStatus, out *csidriverlvm.PluginRolloutStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.DesiredNodes = in.DesiredNodes
+	out.UpdatedNodes = in.UpdatedNodes
+	out.AvailableNodes = in.AvailableNodes
+	return nil
+}
+
+// Convert_v1alpha1_PluginRolloutStatus_To_csidriverlvm_PluginRolloutStatus is an autogenerated conversion function.
+func Convert_v1alpha1_PluginRolloutStatus_To_csidriverlvm_PluginRolloutStatus(in *PluginRolloutStatus, out *csidriverlvm.PluginRolloutStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PluginRolloutStatus_To_csidriverlvm_PluginRolloutStatus(in, out, s)
+}
+
+func autoConvert_csidriverlvm_PluginRolloutStatus_To_v1alpha1_PluginRolloutStatus(in *csidriverlvm.PluginRolloutStatus, out *PluginRolloutStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.DesiredNodes = in.DesiredNodes
+	out.UpdatedNodes = in.UpdatedNodes
+	out.AvailableNodes = in.AvailableNodes
+	return nil
+}
+
+// Convert_csidriverlvm_PluginRolloutStatus_To_v1alpha1_PluginRolloutStatus is an autogenerated conversion function.
+func Convert_csidriverlvm_PluginRolloutStatus_To_v1alpha1_PluginRolloutStatus(in *csidriverlvm.PluginRolloutStatus, out *PluginRolloutStatus, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_PluginRolloutStatus_To_v1alpha1_PluginRolloutStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_PluginUpdateStrategy_To_csidriverlvm_PluginUpdateStrategy(in *PluginUpdateStrategy, out *csidriverlvm.PluginUpdateStrategy, s conversion.Scope) error {
+	out.Type = (*appsv1.DaemonSetUpdateStrategyType)(unsafe.Pointer(in.Type))
+	out.MaxUnavailable = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnavailable))
+	out.MaxSurge = (*intstr.IntOrString)(unsafe.Pointer(in.MaxSurge))
+	return nil
+}
+
+// Convert_v1alpha1_PluginUpdateStrategy_To_csidriverlvm_PluginUpdateStrategy is an autogenerated conversion function.
+func Convert_v1alpha1_PluginUpdateStrategy_To_csidriverlvm_PluginUpdateStrategy(in *PluginUpdateStrategy, out *csidriverlvm.PluginUpdateStrategy, s conversion.Scope) error {
+	return autoConvert_v1alpha1_PluginUpdateStrategy_To_csidriverlvm_PluginUpdateStrategy(in, out, s)
+}
+
+func autoConvert_csidriverlvm_PluginUpdateStrategy_To_v1alpha1_PluginUpdateStrategy(in *csidriverlvm.PluginUpdateStrategy, out *PluginUpdateStrategy, s conversion.Scope) error {
+	out.Type = (*appsv1.DaemonSetUpdateStrategyType)(unsafe.Pointer(in.Type))
+	out.MaxUnavailable = (*intstr.IntOrString)(unsafe.Pointer(in.MaxUnavailable))
+	out.MaxSurge = (*intstr.IntOrString)(unsafe.Pointer(in.MaxSurge))
+	return nil
+}
+
+// Convert_csidriverlvm_PluginUpdateStrategy_To_v1alpha1_PluginUpdateStrategy is an autogenerated conversion function.
+func Convert_csidriverlvm_PluginUpdateStrategy_To_v1alpha1_PluginUpdateStrategy(in *csidriverlvm.PluginUpdateStrategy, out *PluginUpdateStrategy, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_PluginUpdateStrategy_To_v1alpha1_PluginUpdateStrategy(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageClass_To_csidriverlvm_StorageClass(in *StorageClass, out *csidriverlvm.StorageClass, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.FsType = (*string)(unsafe.Pointer(in.FsType))
+	out.MountOptions = *(*[]string)(unsafe.Pointer(&in.MountOptions))
+	out.Stripes = (*int32)(unsafe.Pointer(in.Stripes))
+	out.StripeSize = (*resource.Quantity)(unsafe.Pointer(in.StripeSize))
+	out.Mirrors = (*int32)(unsafe.Pointer(in.Mirrors))
+	out.ThinPool = (*csidriverlvm.ThinPool)(unsafe.Pointer(in.ThinPool))
+	out.Encryption = (*csidriverlvm.Encryption)(unsafe.Pointer(in.Encryption))
+	return nil
+}
+
+// Convert_v1alpha1_StorageClass_To_csidriverlvm_StorageClass is an autogenerated conversion function.
+func Convert_v1alpha1_StorageClass_To_csidriverlvm_StorageClass(in *StorageClass, out *csidriverlvm.StorageClass, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageClass_To_csidriverlvm_StorageClass(in, out, s)
+}
+
+func autoConvert_csidriverlvm_StorageClass_To_v1alpha1_StorageClass(in *csidriverlvm.StorageClass, out *StorageClass, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.FsType = (*string)(unsafe.Pointer(in.FsType))
+	out.MountOptions = *(*[]string)(unsafe.Pointer(&in.MountOptions))
+	out.Stripes = (*int32)(unsafe.Pointer(in.Stripes))
+	out.StripeSize = (*resource.Quantity)(unsafe.Pointer(in.StripeSize))
+	out.Mirrors = (*int32)(unsafe.Pointer(in.Mirrors))
+	out.ThinPool = (*ThinPool)(unsafe.Pointer(in.ThinPool))
+	out.Encryption = (*Encryption)(unsafe.Pointer(in.Encryption))
+	return nil
+}
+
+// Convert_csidriverlvm_StorageClass_To_v1alpha1_StorageClass is an autogenerated conversion function.
+func Convert_csidriverlvm_StorageClass_To_v1alpha1_StorageClass(in *csidriverlvm.StorageClass, out *StorageClass, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_StorageClass_To_v1alpha1_StorageClass(in, out, s)
+}
+
+func autoConvert_v1alpha1_ThinPool_To_csidriverlvm_ThinPool(in *ThinPool, out *csidriverlvm.ThinPool, s conversion.Scope) error {
+	out.SizePercentage = (*int32)(unsafe.Pointer(in.SizePercentage))
+	out.OvercommitRatio = (*int32)(unsafe.Pointer(in.OvercommitRatio))
+	return nil
+}
+
+// Convert_v1alpha1_ThinPool_To_csidriverlvm_ThinPool is an autogenerated conversion function.
+func Convert_v1alpha1_ThinPool_To_csidriverlvm_ThinPool(in *ThinPool, out *csidriverlvm.ThinPool, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ThinPool_To_csidriverlvm_ThinPool(in, out, s)
+}
+
+func autoConvert_csidriverlvm_ThinPool_To_v1alpha1_ThinPool(in *csidriverlvm.ThinPool, out *ThinPool, s conversion.Scope) error {
+	out.SizePercentage = (*int32)(unsafe.Pointer(in.SizePercentage))
+	out.OvercommitRatio = (*int32)(unsafe.Pointer(in.OvercommitRatio))
+	return nil
+}
+
+// Convert_csidriverlvm_ThinPool_To_v1alpha1_ThinPool is an autogenerated conversion function.
+func Convert_csidriverlvm_ThinPool_To_v1alpha1_ThinPool(in *csidriverlvm.ThinPool, out *ThinPool, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_ThinPool_To_v1alpha1_ThinPool(in, out, s)
+}
+
+func autoConvert_v1alpha1_WorkerPoolStatus_To_csidriverlvm_WorkerPoolStatus(in *WorkerPoolStatus, out *csidriverlvm.WorkerPoolStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.MachineImage = in.MachineImage
+	out.HostWritePath = in.HostWritePath
+	out.KubeletRootDir = in.KubeletRootDir
+	return nil
+}
+
+// Convert_v1alpha1_WorkerPoolStatus_To_csidriverlvm_WorkerPoolStatus is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerPoolStatus_To_csidriverlvm_WorkerPoolStatus(in *WorkerPoolStatus, out *csidriverlvm.WorkerPoolStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerPoolStatus_To_csidriverlvm_WorkerPoolStatus(in, out, s)
+}
+
+func autoConvert_csidriverlvm_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in *csidriverlvm.WorkerPoolStatus, out *WorkerPoolStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.MachineImage = in.MachineImage
+	out.HostWritePath = in.HostWritePath
+	out.KubeletRootDir = in.KubeletRootDir
+	return nil
+}
+
+// Convert_csidriverlvm_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus is an autogenerated conversion function.
+func Convert_csidriverlvm_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in *csidriverlvm.WorkerPoolStatus, out *WorkerPoolStatus, s conversion.Scope) error {
+	return autoConvert_csidriverlvm_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in, out, s)
+}
@@ -10,7 +10,10 @@
 package csidriverlvm
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -27,6 +30,65 @@ func (in *CsiDriverLvmConfig) DeepCopyInto(out *CsiDriverLvmConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.StorageClasses != nil {
+		in, out := &in.StorageClasses, &out.StorageClasses
+		*out = make([]StorageClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LvmConfig != nil {
+		in, out := &in.LvmConfig, &out.LvmConfig
+		*out = new(LvmConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LoopDevices != nil {
+		in, out := &in.LoopDevices, &out.LoopDevices
+		*out = new(LoopDevices)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = new(Paths)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HighAvailability != nil {
+		in, out := &in.HighAvailability, &out.HighAvailability
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PluginUpdateStrategy != nil {
+		in, out := &in.PluginUpdateStrategy, &out.PluginUpdateStrategy
+		*out = new(PluginUpdateStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReclaimPolicy != nil {
+		in, out := &in.ReclaimPolicy, &out.ReclaimPolicy
+		*out = new(v1.PersistentVolumeReclaimPolicy)
+		**out = **in
+	}
+	if in.LogLevel != nil {
+		in, out := &in.LogLevel, &out.LogLevel
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DefaultStorageClass != nil {
+		in, out := &in.DefaultStorageClass, &out.DefaultStorageClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]Patch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -47,3 +109,374 @@ func (in *CsiDriverLvmConfig) DeepCopyObject() runtime.Object {
 	}
 	return nil
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CsiDriverLvmStatus) DeepCopyInto(out *CsiDriverLvmStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.EncryptionKeys != nil {
+		in, out := &in.EncryptionKeys, &out.EncryptionKeys
+		*out = make([]EncryptionKeyStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WorkerPools != nil {
+		in, out := &in.WorkerPools, &out.WorkerPools
+		*out = make([]WorkerPoolStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginRollouts != nil {
+		in, out := &in.PluginRollouts, &out.PluginRollouts
+		*out = make([]PluginRolloutStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ManagedResources != nil {
+		in, out := &in.ManagedResources, &out.ManagedResources
+		*out = make([]ManagedResourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CsiDriverLvmStatus.
+func (in *CsiDriverLvmStatus) DeepCopy() *CsiDriverLvmStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CsiDriverLvmStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CsiDriverLvmStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Encryption) DeepCopyInto(out *Encryption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Encryption.
+func (in *Encryption) DeepCopy() *Encryption {
+	if in == nil {
+		return nil
+	}
+	out := new(Encryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EncryptionKeyStatus) DeepCopyInto(out *EncryptionKeyStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousChecksums != nil {
+		in, out := &in.PreviousChecksums, &out.PreviousChecksums
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EncryptionKeyStatus.
+func (in *EncryptionKeyStatus) DeepCopy() *EncryptionKeyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EncryptionKeyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoopDevices) DeepCopyInto(out *LoopDevices) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoopDevices.
+func (in *LoopDevices) DeepCopy() *LoopDevices {
+	if in == nil {
+		return nil
+	}
+	out := new(LoopDevices)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LvmConfig) DeepCopyInto(out *LvmConfig) {
+	*out = *in
+	if in.DeviceFilter != nil {
+		in, out := &in.DeviceFilter, &out.DeviceFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IssueDiscards != nil {
+		in, out := &in.IssueDiscards, &out.IssueDiscards
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ThinPoolAutoextendThreshold != nil {
+		in, out := &in.ThinPoolAutoextendThreshold, &out.ThinPoolAutoextendThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ThinPoolAutoextendPercent != nil {
+		in, out := &in.ThinPoolAutoextendPercent, &out.ThinPoolAutoextendPercent
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LvmConfig.
+func (in *LvmConfig) DeepCopy() *LvmConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LvmConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManagedResourceStatus) DeepCopyInto(out *ManagedResourceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManagedResourceStatus.
+func (in *ManagedResourceStatus) DeepCopy() *ManagedResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ManagedResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Patch) DeepCopyInto(out *Patch) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Patch.
+func (in *Patch) DeepCopy() *Patch {
+	if in == nil {
+		return nil
+	}
+	out := new(Patch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Paths) DeepCopyInto(out *Paths) {
+	*out = *in
+	if in.KubeletRootDir != nil {
+		in, out := &in.KubeletRootDir, &out.KubeletRootDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.PluginDir != nil {
+		in, out := &in.PluginDir, &out.PluginDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.RegistrationDir != nil {
+		in, out := &in.RegistrationDir, &out.RegistrationDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.DevDir != nil {
+		in, out := &in.DevDir, &out.DevDir
+		*out = new(string)
+		**out = **in
+	}
+	if in.ModulesDir != nil {
+		in, out := &in.ModulesDir, &out.ModulesDir
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Paths.
+func (in *Paths) DeepCopy() *Paths {
+	if in == nil {
+		return nil
+	}
+	out := new(Paths)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginRolloutStatus) DeepCopyInto(out *PluginRolloutStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginRolloutStatus.
+func (in *PluginRolloutStatus) DeepCopy() *PluginRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginUpdateStrategy) DeepCopyInto(out *PluginUpdateStrategy) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(appsv1.DaemonSetUpdateStrategyType)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginUpdateStrategy.
+func (in *PluginUpdateStrategy) DeepCopy() *PluginUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageClass) DeepCopyInto(out *StorageClass) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.FsType != nil {
+		in, out := &in.FsType, &out.FsType
+		*out = new(string)
+		**out = **in
+	}
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stripes != nil {
+		in, out := &in.Stripes, &out.Stripes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StripeSize != nil {
+		in, out := &in.StripeSize, &out.StripeSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ThinPool != nil {
+		in, out := &in.ThinPool, &out.ThinPool
+		*out = new(ThinPool)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(Encryption)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageClass.
+func (in *StorageClass) DeepCopy() *StorageClass {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPool) DeepCopyInto(out *ThinPool) {
+	*out = *in
+	if in.SizePercentage != nil {
+		in, out := &in.SizePercentage, &out.SizePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OvercommitRatio != nil {
+		in, out := &in.OvercommitRatio, &out.OvercommitRatio
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPool.
+func (in *ThinPool) DeepCopy() *ThinPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerPoolStatus) DeepCopyInto(out *WorkerPoolStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerPoolStatus.
+func (in *WorkerPoolStatus) DeepCopy() *WorkerPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	controllercmd "github.com/gardener/gardener/extensions/pkg/controller/cmd"
 	extensionsheartbeatcontroller "github.com/gardener/gardener/extensions/pkg/controller/heartbeat"
+	webhookcmd "github.com/gardener/gardener/extensions/pkg/webhook/cmd"
 
 	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/webhook/operatingsystemconfig"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/webhook/provisioner"
 )
 
 // ControllerSwitchOptions are the controllercmd.SwitchOptions for the provider controllers.
@@ -14,3 +17,11 @@ func ControllerSwitchOptions() *controllercmd.SwitchOptions {
 		controllercmd.Switch(extensionsheartbeatcontroller.ControllerName, extensionsheartbeatcontroller.AddToManager),
 	)
 }
+
+// WebhookSwitchOptions are the webhookcmd.SwitchOptions for the extension webhooks.
+func WebhookSwitchOptions() *webhookcmd.SwitchOptions {
+	return webhookcmd.NewSwitchOptions(
+		webhookcmd.Switch(operatingsystemconfig.WebhookName, operatingsystemconfig.AddToManager),
+		webhookcmd.Switch(provisioner.WebhookName, provisioner.AddToManager),
+	)
+}
@@ -2,32 +2,32 @@ package csidriverlvm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync/atomic"
 	"time"
 
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
+	"github.com/gardener/gardener/pkg/chartrenderer"
 
-	gutil "github.com/gardener/gardener/extensions/pkg/util"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
-	"github.com/gardener/gardener/pkg/client/kubernetes"
+	reconcilerutils "github.com/gardener/gardener/pkg/controllerutils/reconciler"
 	"github.com/gardener/gardener/pkg/utils/managedresources"
 
-	extensionsconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
 	"github.com/go-logr/logr"
 	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
 	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
-	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/imagevector"
 	"github.com/metal-stack/metal-lib/pkg/pointer"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
-	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,28 +35,64 @@ import (
 
 const (
 	shootNamespace string = "kube-system"
-	provisioner    string = "lvm.csi.metal-stack.io"
 
 	oldName        string = "csi-lvm"
 	oldNamespace   string = "csi-lvm"
 	oldProvisioner string = "metal-stack.io/csi-lvm"
 
 	pullPolicy corev1.PullPolicy = corev1.PullIfNotPresent
+
+	fsTypeParameter     string = "csi.storage.k8s.io/fstype"
+	stripesParameter    string = "stripes"
+	stripeSizeParameter string = "stripeSize"
+	mirrorsParameter    string = "mirrors"
+
+	thinPoolSizeParameter    string = "thinPoolSize"
+	overcommitRatioParameter string = "overcommitRatio"
+
+	oldCsiLvmCheckRequeueInterval = 30 * time.Second
 )
 
+// defaultStorageClasses are deployed into every shoot, "csi-lvm" mimics the storage class of the old csi-lvm
+var defaultStorageClasses = []struct {
+	name       string
+	volumeType string
+}{
+	{name: "csi-lvm", volumeType: v1alpha1.VolumeTypeLinear},
+	{name: "csi-driver-lvm-linear", volumeType: v1alpha1.VolumeTypeLinear},
+	{name: "csi-driver-lvm-mirror", volumeType: v1alpha1.VolumeTypeMirror},
+	{name: "csi-driver-lvm-striped", volumeType: v1alpha1.VolumeTypeStriped},
+}
+
 // NewActuator returns an actuator responsible for Extension resources.
-func NewActuator(mgr manager.Manager, config config.ControllerConfiguration) extension.Actuator {
+func NewActuator(mgr manager.Manager, config config.ControllerConfiguration, shootWebhookConfig *atomic.Value) extension.Actuator {
+	return newActuator(mgr, config, shootWebhookConfig)
+}
+
+func newActuator(mgr manager.Manager, config config.ControllerConfiguration, shootWebhookConfig *atomic.Value) *actuator {
 	return &actuator{
-		client:  mgr.GetClient(),
-		decoder: serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder(),
-		config:  config,
+		client:             mgr.GetClient(),
+		decoder:            serializer.NewCodecFactory(mgr.GetScheme(), serializer.EnableStrict).UniversalDecoder(),
+		config:             config,
+		shootClients:       newShootClientCache(mgr.GetClient()),
+		chartRenderer:      newChartRenderer(),
+		shootWebhookConfig: shootWebhookConfig,
+		requeues:           newRequeues(),
 	}
 }
 
 type actuator struct {
-	client  client.Client
-	decoder runtime.Decoder
-	config  config.ControllerConfiguration
+	client        client.Client
+	decoder       runtime.Decoder
+	config        config.ControllerConfiguration
+	shootClients  *shootClientCache
+	chartRenderer chartrenderer.Interface
+	// shootWebhookConfig contains the extensionswebhook.Configs of the webhooks in the shoots, it is nil if the
+	// webhooks are disabled
+	shootWebhookConfig *atomic.Value
+	// requeues records the extensions which have to be reconciled again, it is nil if the actuator is not run by the
+	// controller
+	requeues *requeues
 }
 
 // Reconcile the Extension resource.
@@ -69,719 +105,403 @@ func (a *actuator) Reconcile(ctx context.Context, log logr.Logger, ex *extension
 		}
 	}
 
-	csidriverlvmConfig.ConfigureDefaults(a.config.DefaultHostWritePath, a.config.DefaultDevicePattern)
-	if !csidriverlvmConfig.IsValid(log) {
-		return fmt.Errorf("invalid csi-driver-lvm configuration")
+	cluster, err := extensionscontroller.GetCluster(ctx, a.client, ex.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster: %w", err)
 	}
 
-	isOldCsiLvmExisting, err := a.isOldCsiLvmExisting(ctx, ex.Namespace)
-	if err != nil {
-		return fmt.Errorf("failed to check if old csi-lvm is existing: %w", err)
+	if err := validateCluster(cluster); err != nil {
+		return err
 	}
-	if isOldCsiLvmExisting {
-		log.Info("old csi-lvm is existing, skipping reconciliation")
+	// the api server of a hibernated shoot is not available, the managed resource is kept as it is until the shoot wakes up
+	if cluster.Shoot != nil && extensionscontroller.IsHibernationEnabled(cluster) {
+		log.Info("shoot is hibernated, skipping reconciliation")
 		return nil
 	}
 
-	controllerObjects, err := a.controllerObjects()
+	status, err := a.providerStatus(ex)
 	if err != nil {
 		return err
 	}
 
-	pluginObjects, err := a.pluginObjects(csidriverlvmConfig)
+	desiredImages, err := findImages()
 	if err != nil {
 		return err
 	}
+	previousImages := status.Images
+	if len(previousImages) == 0 {
+		previousImages, err = a.previousImages(ctx, ex.Namespace, desiredImages)
+		if err != nil {
+			return err
+		}
+	}
+	imageSet, targetImages := rolloutImages(a.config.RolloutPolicy, desiredImages, previousImages, cluster)
+	now := time.Now()
+	images, held := effectiveImages(targetImages, previousImages, cluster, now, ex.Annotations[v1alpha1.ForceImageUpdateAnnotation] == "true")
+	if held {
+		// the extension is not reconciled periodically, it is requeued for the beginning of the maintenance window
+		requeueAfter := untilMaintenanceWindow(cluster, now)
+		log.Info("holding back image changes until the maintenance window of the shoot", "requeueAfter", requeueAfter)
+		a.requeues.set(client.ObjectKeyFromObject(ex), requeueAfter)
+	}
+
+	workerPools, err := prepareConfig(log, csidriverlvmConfig, a.config, cluster)
+	if err != nil {
+		return err
+	}
+
+	shootClient, release, err := a.shootClients.Get(ctx, ex.Namespace)
+	if err != nil {
+		return &reconcilerutils.RequeueAfterError{
+			Cause:        v1beta1helper.NewErrorWithCodes(fmt.Errorf("failed to create shoot client: %w", err), gardencorev1beta1.ErrorRetryableInfraDependencies),
+			RequeueAfter: oldCsiLvmCheckRequeueInterval,
+		}
+	}
+	defer release()
+
+	isOldCsiLvmExisting, err := a.checkOldCsiLvm(ctx, ex, shootClient, status)
+	if err != nil {
+		// the credentials of the cached client may have been revoked before the kubeconfig secret is updated
+		if apierrors.IsUnauthorized(err) {
+			a.shootClients.Invalidate(ex.Namespace, invalidationReasonUnauthorized)
+		}
+		return &reconcilerutils.RequeueAfterError{
+			Cause:        v1beta1helper.NewErrorWithCodes(fmt.Errorf("failed to check if old csi-lvm is existing: %w", err), gardencorev1beta1.ErrorRetryableInfraDependencies),
+			RequeueAfter: oldCsiLvmCheckRequeueInterval,
+		}
+	}
+	if isOldCsiLvmExisting {
+		log.Info("old csi-lvm is existing, skipping reconciliation")
+		if err := a.updateProviderStatus(ctx, ex, status); err != nil {
+			return fmt.Errorf("failed to update provider status: %w", err)
+		}
+		return a.removeAnnotation(ctx, ex, v1alpha1.RecheckOldCsiLvmAnnotation)
+	}
 
-	objects := []client.Object{}
-	objects = append(objects, controllerObjects...)
-	objects = append(objects, pluginObjects...)
+	groups := pluginGroups(csidriverlvmConfig, a.config, workerPools)
+	highAvailability := isHighAvailability(csidriverlvmConfig, cluster)
+	multiZonal := isMultiZonal(cluster)
 
-	shootResources, err := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer).AddAllAndSerialize(objects...)
+	var checksumKey []byte
+	if isEncrypted(csidriverlvmConfig) {
+		checksumKey, err = a.encryptionChecksumKey(ctx, ex.Namespace)
+		if err != nil {
+			return err
+		}
+	}
+	encryptionSecrets, encryptionChecksums, err := encryptionSecrets(ctx, a.client, ex.Namespace, cluster, csidriverlvmConfig, checksumKey)
+	if err != nil {
+		return err
+	}
+	encryptionKeys, retainedSecrets, err := retainEncryptionKeys(ctx, shootClient, status.EncryptionKeys, encryptionChecksums, metav1.Now())
+	if err != nil {
+		return err
+	}
+	encryption := encryptionObjects(append(encryptionSecrets, retainedSecrets...))
+
+	hash, err := renderInput{
+		Config:           csidriverlvmConfig,
+		AllowedPatches:   a.config.AllowedPatches,
+		DefaultPaths:     a.config.DefaultPaths,
+		WorkerPools:      workerPools,
+		Images:           images,
+		HighAvailability: highAvailability,
+		MultiZonal:       multiZonal,
+		EncryptionKeys:   encryptionKeys,
+	}.hash()
 	if err != nil {
 		return err
 	}
 
-	err = managedresources.CreateForShoot(ctx, a.client, ex.Namespace, v1alpha1.ShootCsiDriverLvmResourceName, "csi-driver-lvm-extension", false, shootResources)
+	legacyExisting, err := a.releaseLegacyManagedResource(ctx, ex.Namespace)
+	if err != nil {
+		return err
+	}
 
+	outdated, err := a.outdatedManagedResources(ctx, ex.Namespace, hash)
 	if err != nil {
 		return err
 	}
 
-	log.Info("managed resource created succesfully", "name", v1alpha1.ShootCsiDriverLvmResourceName)
+	if len(outdated) == 0 {
+		log.Info("managed resources are up to date, skipping update")
+	} else {
+		components, err := a.shootComponents(csidriverlvmConfig, groups, images, highAvailability, multiZonal, encryptionChecksums, encryption)
+		if err != nil {
+			return err
+		}
 
-	return nil
-}
+		// a failing component must not block the rollout of the other components
+		var errs []error
+		for _, component := range components {
+			if !slices.Contains(outdated, component.managedResourceName) {
+				continue
+			}
 
-// Delete the Extension resource.
-func (a *actuator) Delete(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
+			if err := a.deployManagedResource(ctx, ex.Namespace, component, keepObjects(csidriverlvmConfig), hash); err != nil {
+				errs = append(errs, err)
+				continue
+			}
 
-	log.Info("deleting managed resource")
-	err := managedresources.Delete(ctx, a.client, ex.Namespace, v1alpha1.ShootCsiDriverLvmResourceName, false)
+			log.Info("managed resource created succesfully", "name", component.managedResourceName)
+		}
+		if err := errors.Join(errs...); err != nil {
+			return err
+		}
+	}
 
-	if err != nil {
+	if err := a.reconcileShootWebhooks(ctx, ex.Namespace, cluster, lvmConf(csidriverlvmConfig) != ""); err != nil {
 		return err
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer cancel()
+	// the objects of the legacy managed resource have been adopted by the managed resources of the components
+	if legacyExisting {
+		if err := managedresources.DeleteForShoot(ctx, a.client, ex.Namespace, v1alpha1.ShootCsiDriverLvmResourceName); err != nil {
+			return fmt.Errorf("failed to delete legacy managed resource: %w", err)
+		}
+		log.Info("migrated legacy managed resource", "name", v1alpha1.ShootCsiDriverLvmResourceName)
+	}
+
+	// the status is updated before an unhealthy component fails the reconciliation
+	var healthErr error
+	if a.config.HealthTimeout != nil {
+		healthErr = a.waitUntilHealthy(ctx, ex.Namespace, shootClient, groups, a.config.HealthTimeout.Duration)
+	}
 
-	err = managedresources.WaitUntilDeleted(timeoutCtx, a.client, ex.Namespace, v1alpha1.ShootCsiDriverLvmResourceName)
+	managedResources, err := a.managedResourceStatus(ctx, ex.Namespace)
 	if err != nil {
 		return err
 	}
 
-	log.Info("successfully deleted managed resource")
+	status.Images = images
+	status.ImageSet = imageSet
+	status.EncryptionKeys = encryptionKeys
+	status.WorkerPools = workerPools
+	status.ManagedResources = managedResources
 
-	return nil
-}
+	// the rollout progress is informational, it must not fail the reconciliation
+	rollouts, err := pluginRollouts(ctx, shootClient, groups)
+	if err != nil {
+		log.Error(err, "failed to get rollout progress of the plugin")
+	} else {
+		status.PluginRollouts = rollouts
+	}
 
-// ForceDelete the Extension resource
-func (a *actuator) ForceDelete(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
-	return nil
-}
+	err = a.updateProviderStatus(ctx, ex, status)
+	if err != nil {
+		return fmt.Errorf("failed to update provider status: %w", err)
+	}
 
-// Restore the Extension resource.
-func (a *actuator) Restore(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
-	return a.Reconcile(ctx, log, ex)
+	// the images are recorded in the provider status, the forced update is complete
+	for _, annotation := range []string{v1alpha1.RecheckOldCsiLvmAnnotation, v1alpha1.ForceImageUpdateAnnotation} {
+		if err := a.removeAnnotation(ctx, ex, annotation); err != nil {
+			return err
+		}
+	}
+
+	return healthErr
 }
 
-// Migrate the Extension resource.
-func (a *actuator) Migrate(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
+// validateCluster returns an error if the extension is not applicable for the shoot of the cluster
+func validateCluster(cluster *extensionscontroller.Cluster) error {
+	if cluster != nil && cluster.Shoot != nil && v1beta1helper.IsWorkerless(cluster.Shoot) {
+		return v1beta1helper.NewErrorWithCodes(errors.New("csi-driver-lvm is not applicable for workerless shoots"), gardencorev1beta1.ErrorConfigurationProblem)
+	}
 	return nil
 }
 
-func (a *actuator) controllerObjects() ([]client.Object, error) {
+// prepareConfig applies the purpose profile and the defaults of the operator to the configuration of the shoot and
+// validates it, it returns the settings of the worker pools
+func prepareConfig(log logr.Logger, csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, cluster *extensionscontroller.Cluster) ([]v1alpha1.WorkerPoolStatus, error) {
+	applyPurposeProfile(csidriverlvmConfig, controllerConfig, cluster)
+	workerPools := WorkerPools(csidriverlvmConfig, controllerConfig, cluster)
 
-	csidriverlvmServiceAccountController := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-controller",
-			Namespace: shootNamespace,
-		},
+	csidriverlvmConfig.ConfigureDefaults(controllerConfig.DefaultHostWritePath, controllerConfig.DefaultDevicePattern)
+	if !csidriverlvmConfig.IsValid(log) {
+		return nil, fmt.Errorf("invalid csi-driver-lvm configuration")
 	}
 
-	csidriverlvmClusterRoleController := &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-controller",
-			Namespace: shootNamespace,
-		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumes"},
-				Verbs:     []string{"get", "list", "watch", "update", "patch", "create", "delete"},
-			},
-			{
-				APIGroups: []string{"storage.k8s.io"},
-				Resources: []string{"csinodes"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{"storage.k8s.io"},
-				Resources: []string{"volumeattachments"},
-				Verbs:     []string{"get", "list", "watch", "update", "patch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumeclaims"},
-				Verbs:     []string{"get", "list", "watch", "update", "patch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumeclaims/status"},
-				Verbs:     []string{"update", "patch"},
-			},
-			{
-				APIGroups: []string{"storage.k8s.io"},
-				Resources: []string{"storageclasses"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"events"},
-				Verbs:     []string{"get", "list", "watch", "update", "patch", "create", "delete"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"nodes"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{"storage.k8s.io"},
-				Resources: []string{"volumeattachments/status"},
-				Verbs:     []string{"patch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"pods"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-		},
+	if err := validateConfig(csidriverlvmConfig, controllerConfig, cluster); err != nil {
+		return nil, fmt.Errorf("csi-driver-lvm configuration is not permitted: %w", err)
 	}
-
-	csidriverlvmClusterRoleBindingController := &rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-controller",
-			Namespace: shootNamespace,
-		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      "csi-driver-lvm-controller",
-				Namespace: shootNamespace,
-			},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     "csi-driver-lvm-controller",
-		},
+	if err := validateWorkerPools(workerPools); err != nil {
+		return nil, fmt.Errorf("csi-driver-lvm configuration is not permitted: %w", err)
 	}
 
-	csiAttacherImage, err := imagevector.ImageVector().FindImage("csi-attacher")
+	return workerPools, nil
+}
+
+// shootComponents renders the objects of the components deployed into the shoot and applies the patches of the shoot
+func (a *actuator) shootComponents(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, groups []pluginGroup, images map[string]string, highAvailability, multiZonal bool, encryptionChecksums map[string]string, encryptionObjects []client.Object) ([]shootComponent, error) {
+	objects, err := a.renderShootChart(csidriverlvmConfig, groups, images, highAvailability, multiZonal, encryptionChecksums)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-attacher image: %w", err)
+		return nil, err
 	}
 
-	csiResizerImage, err := imagevector.ImageVector().FindImage("csi-resizer")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-resizer image: %w", err)
+	components := []shootComponent{
+		{managedResourceName: v1alpha1.ShootCsiDriverLvmPluginResourceName, objects: objects[v1alpha1.ShootCsiDriverLvmPluginResourceName]},
+		{managedResourceName: v1alpha1.ShootCsiDriverLvmControllerResourceName, objects: objects[v1alpha1.ShootCsiDriverLvmControllerResourceName]},
+		{managedResourceName: v1alpha1.ShootCsiDriverLvmStorageClassesResourceName, objects: append(objects[v1alpha1.ShootCsiDriverLvmStorageClassesResourceName], encryptionObjects...)},
 	}
 
-	csiProvisionerImage, err := imagevector.ImageVector().FindImage("csi-provisioner")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-provisioner image: %w", err)
+	if err := applyPatches(components, csidriverlvmConfig.Patches, a.config.AllowedPatches); err != nil {
+		return nil, fmt.Errorf("csi-driver-lvm configuration is not permitted: %w", err)
 	}
 
-	var hostPathType corev1.HostPathType = corev1.HostPathDirectoryOrCreate
+	return components, nil
+}
 
-	csidriverlvmStatefulsetController := &appsv1.StatefulSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        "csi-driver-lvm-controller",
-			Namespace:   shootNamespace,
-			Annotations: map[string]string{},
-			Labels:      map[string]string{},
-		},
-		Spec: appsv1.StatefulSetSpec{
-			Replicas:    ptr.To(int32(1)),
-			ServiceName: "csi-driver-lvm-controller",
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": "csi-driver-lvm-controller",
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "csi-driver-lvm-controller",
-					},
-					Annotations: map[string]string{},
-				},
-				Spec: corev1.PodSpec{
-					Affinity: &corev1.Affinity{
-						PodAntiAffinity: &corev1.PodAntiAffinity{
-							RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
-								{
-									LabelSelector: &metav1.LabelSelector{
-										MatchExpressions: []metav1.LabelSelectorRequirement{
-											{
-												Key:      "app",
-												Operator: "In",
-												Values:   []string{"csi-driver-lvm-controller"},
-											},
-										},
-									},
-									TopologyKey: "kubernetes.io/hostname",
-								},
-							},
-						},
-					},
-					ServiceAccountName: "csi-driver-lvm-controller",
-					Containers: []corev1.Container{
-						{
-							Name:            "csi-attacher",
-							Image:           csiAttacherImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args:            []string{"--v=5", "--csi-address=/csi/csi.sock"},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(true),
-								Privileged:             pointer.Pointer(true),
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-							},
-						},
-						{
-							Name:            "csi-provisioner",
-							Image:           csiProvisionerImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args:            []string{"--v=5", "--csi-address=/csi/csi.sock", "--feature-gates=Topology=true"},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(true),
-								Privileged:             pointer.Pointer(true),
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-							},
-						},
-						{
-							Name:            "csi-resizer",
-							Image:           csiResizerImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args:            []string{"--v=5", "--csi-address=/csi/csi.sock"},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(true),
-								Privileged:             pointer.Pointer(true),
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "socket-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/lib/kubelet/plugins/csi-driver-lvm",
-									Type: &hostPathType,
-								},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
+// Delete the Extension resource.
+func (a *actuator) Delete(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
 
-	objects := []client.Object{
-		csidriverlvmServiceAccountController,
-		csidriverlvmClusterRoleController,
-		csidriverlvmClusterRoleBindingController,
-		csidriverlvmStatefulsetController,
-	}
+	// the legacy managed resource of previous versions and the webhooks are deleted first, the legacy managed resource
+	// does not exist after the migration
+	names := append([]string{v1alpha1.ShootCsiDriverLvmResourceName, v1alpha1.ShootCsiDriverLvmWebhooksResourceName}, shootComponentNames...)
+	slices.Reverse(names[2:])
 
-	return objects, nil
-}
+	for _, name := range names {
+		log.Info("deleting managed resource", "name", name)
+		err := managedresources.DeleteForShoot(ctx, a.client, ex.Namespace, name)
+		if err != nil {
+			return err
+		}
 
-func (a *actuator) pluginObjects(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) ([]client.Object, error) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		err = managedresources.WaitUntilDeleted(timeoutCtx, a.client, ex.Namespace, name)
+		cancel()
+		if err != nil {
+			return err
+		}
 
-	csidriverlvmDriver := &storagev1.CSIDriver{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm",
-			Namespace: shootNamespace,
-		},
-		Spec: storagev1.CSIDriverSpec{
-			VolumeLifecycleModes: []storagev1.VolumeLifecycleMode{"Persistent", "Ephemeral"},
-			PodInfoOnMount:       pointer.Pointer(true),
-			AttachRequired:       pointer.Pointer(false),
-		},
+		log.Info("successfully deleted managed resource", "name", name)
 	}
 
-	csidriverlvmServiceAccountPlugin := &corev1.ServiceAccount{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-plugin",
-			Namespace: shootNamespace,
-		},
+	if err := client.IgnoreNotFound(a.client.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ex.Namespace, Name: encryptionChecksumKeyName}})); err != nil {
+		return fmt.Errorf("failed to delete encryption checksum key: %w", err)
 	}
 
-	csidriverlvmClusterRolePlugin := &rbacv1.ClusterRole{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-plugin",
-			Namespace: shootNamespace,
-		},
-		Rules: []rbacv1.PolicyRule{
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumes"},
-				Verbs:     []string{"get", "list", "watch", "update", "patch", "create", "delete"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumeclaims"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"persistentvolumeclaims/status"},
-				Verbs:     []string{"update", "patch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"events"},
-				Verbs:     []string{"list", "watch", "update", "patch", "create"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"nodes"},
-				Verbs:     []string{"get", "list", "watch"},
-			},
-			{
-				APIGroups: []string{""},
-				Resources: []string{"pods"},
-				Verbs:     []string{"get", "list", "watch", "create", "delete"},
-			},
-		},
-	}
+	a.shootClients.Invalidate(ex.Namespace, invalidationReasonDeletion)
 
-	csidriverlvmClusterRoleBindingPlugin := &rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-plugin",
-			Namespace: shootNamespace,
-		},
-		Subjects: []rbacv1.Subject{
-			{
-				Kind:      "ServiceAccount",
-				Name:      "csi-driver-lvm-plugin",
-				Namespace: shootNamespace,
-			},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: "rbac.authorization.k8s.io",
-			Kind:     "ClusterRole",
-			Name:     "csi-driver-lvm-plugin",
-		},
-	}
+	return nil
+}
 
-	var reclaimPolicy corev1.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimDelete
-	var volumeBindingMode storagev1.VolumeBindingMode = storagev1.VolumeBindingWaitForFirstConsumer
+// ForceDelete the Extension resource
+func (a *actuator) ForceDelete(_ context.Context, _ logr.Logger, _ *extensionsv1alpha1.Extension) error {
+	return nil
+}
 
-	csidriverlvmLinearStorageClass := &storagev1.StorageClass{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "csi-driver-lvm-linear",
-		},
-		Provisioner:          "lvm.csi.metal-stack.io",
-		ReclaimPolicy:        &reclaimPolicy,
-		VolumeBindingMode:    &volumeBindingMode,
-		AllowVolumeExpansion: pointer.Pointer(true),
-		Parameters: map[string]string{
-			"type": "linear",
-		},
-	}
+// Restore the Extension resource.
+func (a *actuator) Restore(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
+	return a.Reconcile(ctx, log, ex)
+}
 
-	csidriverlvmMirrorStorageClass := &storagev1.StorageClass{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "csi-driver-lvm-mirror",
-		},
-		Provisioner:          "lvm.csi.metal-stack.io",
-		ReclaimPolicy:        &reclaimPolicy,
-		VolumeBindingMode:    &volumeBindingMode,
-		AllowVolumeExpansion: pointer.Pointer(true),
-		Parameters: map[string]string{
-			"type": "mirror",
-		},
-	}
+// Migrate the Extension resource.
+func (a *actuator) Migrate(ctx context.Context, log logr.Logger, ex *extensionsv1alpha1.Extension) error {
+	// the shoot is reconciled by the destination seed from now on, its client must not be kept in the cache
+	a.shootClients.Invalidate(ex.Namespace, invalidationReasonMigration)
 
-	csidriverlvmStripedStorageClass := &storagev1.StorageClass{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "csi-driver-lvm-striped",
-		},
-		Provisioner:          "lvm.csi.metal-stack.io",
-		ReclaimPolicy:        &reclaimPolicy,
-		VolumeBindingMode:    &volumeBindingMode,
-		AllowVolumeExpansion: pointer.Pointer(true),
-		Parameters: map[string]string{
-			"type": "striped",
-		},
-	}
+	return nil
+}
 
-	csidriverlvmDefaultStorageClass := &storagev1.StorageClass{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: "csi-lvm",
-		},
-		Provisioner:          "lvm.csi.metal-stack.io",
-		ReclaimPolicy:        &reclaimPolicy,
-		VolumeBindingMode:    &volumeBindingMode,
-		AllowVolumeExpansion: pointer.Pointer(true),
-		Parameters: map[string]string{
-			"type": "linear",
-		},
+// mergedStorageClasses returns the default storage classes merged with the storage classes configured in the shoot
+func mergedStorageClasses(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) []v1alpha1.StorageClass {
+	configured := map[string]v1alpha1.StorageClass{}
+	for _, sc := range csidriverlvmConfig.StorageClasses {
+		configured[sc.Name] = sc
 	}
 
-	csiNodeDriverRegistrarImage, err := imagevector.ImageVector().FindImage("csi-node-driver-registrar")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-node-driver-registrar image: %w", err)
+	storageClasses := []v1alpha1.StorageClass{}
+	for _, defaultStorageClass := range defaultStorageClasses {
+		sc, ok := configured[defaultStorageClass.name]
+		if !ok {
+			sc = v1alpha1.StorageClass{Name: defaultStorageClass.name}
+		}
+		if sc.Type == nil {
+			sc.Type = pointer.Pointer(defaultStorageClass.volumeType)
+		}
+		storageClasses = append(storageClasses, sc)
 	}
 
-	livenessprobeImage, err := imagevector.ImageVector().FindImage("livenessprobe")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find livenessprobe image: %w", err)
+	for _, sc := range csidriverlvmConfig.StorageClasses {
+		if isDefaultStorageClass(sc.Name) {
+			continue
+		}
+		storageClasses = append(storageClasses, sc)
 	}
 
-	csiDriverLvmImage, err := imagevector.ImageVector().FindImage("csi-driver-lvm")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-driver-lvm image: %w", err)
-	}
+	return storageClasses
+}
 
-	csiDriverLvmProvisionerImage, err := imagevector.ImageVector().FindImage("csi-driver-lvm-provisioner")
-	if err != nil {
-		return nil, fmt.Errorf("failed to find csi-driver-lvm-provisioner image: %w", err)
+// KernelModules returns the kernel modules which need to be loaded on the nodes for the configured volume types
+func KernelModules(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) []string {
+	modules := []string{}
+	for _, sc := range mergedStorageClasses(csidriverlvmConfig) {
+		module := ""
+		switch pointer.SafeDeref(sc.Type) {
+		case v1alpha1.VolumeTypeThin:
+			module = "dm_thin_pool"
+		case v1alpha1.VolumeTypeRaid1, v1alpha1.VolumeTypeRaid5, v1alpha1.VolumeTypeRaid10:
+			module = "dm_raid"
+		}
+		if module != "" && !slices.Contains(modules, module) {
+			modules = append(modules, module)
+		}
 	}
+	return modules
+}
 
-	var terminationPolicy corev1.TerminationMessagePolicy = corev1.TerminationMessageReadFile
-	var mountPropagation corev1.MountPropagationMode = corev1.MountPropagationBidirectional
-
-	var hostPathTypeCreate corev1.HostPathType = corev1.HostPathDirectoryOrCreate
-	var hostPathTypeDir corev1.HostPathType = corev1.HostPathDirectory
+func isDefaultStorageClass(name string) bool {
+	for _, defaultStorageClass := range defaultStorageClasses {
+		if defaultStorageClass.name == name {
+			return true
+		}
+	}
+	return false
+}
 
-	csidriverlvmDaemonSetPlugin := &appsv1.DaemonSet{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "csi-driver-lvm-plugin",
-			Namespace: shootNamespace,
-		},
-		Spec: appsv1.DaemonSetSpec{
-			RevisionHistoryLimit: ptr.To(int32(10)),
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": "csi-driver-lvm-plugin",
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "csi-driver-lvm-plugin",
-					},
-				}, Spec: corev1.PodSpec{
-					ServiceAccountName: "csi-driver-lvm-plugin",
-					Containers: []corev1.Container{
-						{
-							Name:            "csi-node-driver-registrar",
-							Image:           csiNodeDriverRegistrarImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args:            []string{"--v=5", "--csi-address=/csi/csi.sock", "--kubelet-registration-path=/var/lib/kubelet/plugins/csi-driver-lvm/csi.sock"},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(false),
-								Privileged:             pointer.Pointer(true),
-							},
-							Env: []corev1.EnvVar{
-								{
-									Name: "KUBE_NODE_NAME",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											APIVersion: "v1",
-											FieldPath:  "spec.nodeName",
-										},
-									},
-								},
-							},
-							TerminationMessagePath:   "/dev/termination-log",
-							TerminationMessagePolicy: terminationPolicy,
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-								{MountPath: "/var/lib/kubelet/plugins/csi-driver-lvm/csi.sock", Name: "socket-dir"},
-								{MountPath: "/registration", Name: "registration-dir"},
-							},
-						},
-						{
-							Name:            "csi-driver-lvm-plugin",
-							Image:           csiDriverLvmImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args: []string{
-								"--drivername=lvm.csi.metal-stack.io",
-								"--endpoint=unix:///csi/csi.sock",
-								"--hostwritepath=" + pointer.SafeDeref(csidriverlvmConfig.HostWritePath),
-								"--devices=" + pointer.SafeDeref(csidriverlvmConfig.DevicePattern),
-								"--nodeid=$(KUBE_NODE_NAME)",
-								"--vgname=csi-lvm",
-								"--namespace=kube-system",
-								"--provisionerimage=" + csiDriverLvmProvisionerImage.String(),
-								"--pullpolicy=pullPolicy",
-							},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(false),
-								Privileged:             pointer.Pointer(true),
-							},
-							Env: []corev1.EnvVar{
-								{
-									Name: "KUBE_NODE_NAME",
-									ValueFrom: &corev1.EnvVarSource{
-										FieldRef: &corev1.ObjectFieldSelector{
-											APIVersion: "v1",
-											FieldPath:  "spec.nodeName",
-										},
-									},
-								},
-							},
-							LivenessProbe: &corev1.Probe{
-								FailureThreshold:    5,
-								InitialDelaySeconds: 10,
-								PeriodSeconds:       2,
-								SuccessThreshold:    1,
-								TimeoutSeconds:      3,
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										Path:   "/healthz",
-										Port:   intstr.FromInt(9898),
-										Scheme: corev1.URISchemeHTTP,
-									},
-								},
-							},
-							Ports: []corev1.ContainerPort{{
-								Name:          "healthz",
-								Protocol:      corev1.ProtocolTCP,
-								ContainerPort: 9898,
-							}},
-							TerminationMessagePath:   "/dev/termination-log",
-							TerminationMessagePolicy: terminationPolicy,
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-								{MountPath: "/var/lib/kubelet/pods", Name: "mountpoint-dir", MountPropagation: &mountPropagation},
-								{MountPath: "/var/lib/kubelet/plugins", Name: "plugins-dir", MountPropagation: &mountPropagation},
-								{MountPath: "/dev", Name: "dev-dir", MountPropagation: &mountPropagation},
-								{MountPath: "/lib/modules", Name: "mod-dir"},
-								{MountPath: "/etc/lvm/backup", Name: "lvmbackup", MountPropagation: &mountPropagation},
-								{MountPath: "/etc/lvm/cache", Name: "lvmcache", MountPropagation: &mountPropagation},
-								{MountPath: "/etc/lvm/archive", Name: "lvmarchive", MountPropagation: &mountPropagation},
-								{MountPath: "/etc/lvm/lock", Name: "lvmlock", MountPropagation: &mountPropagation},
-							},
-						},
-						{
-							Name:            "livenessprobe",
-							Image:           livenessprobeImage.String(),
-							ImagePullPolicy: pullPolicy,
-							Args: []string{
-								"--csi-address=/csi/csi.sock",
-								"--health-port=9898",
-							},
-							SecurityContext: &corev1.SecurityContext{
-								ReadOnlyRootFilesystem: pointer.Pointer(true),
-							},
-							TerminationMessagePath:   "/dev/termination-log",
-							TerminationMessagePolicy: terminationPolicy,
-							VolumeMounts: []corev1.VolumeMount{
-								{MountPath: "/csi", Name: "socket-dir"},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "socket-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/lib/kubelet/plugins/csi-driver-lvm",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-						{
-							Name: "mountpoint-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/lib/kubelet/pods",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-						{
-							Name: "registration-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/lib/kubelet/plugins_registry",
-									Type: &hostPathTypeDir,
-								},
-							},
-						},
-						{
-							Name: "plugins-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/var/lib/kubelet/plugins",
-									Type: &hostPathTypeDir,
-								},
-							},
-						},
-						{
-							Name: "dev-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/dev",
-									Type: &hostPathTypeDir,
-								},
-							},
-						},
-						{
-							Name: "mod-dir",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: "/lib/modules",
-								},
-							},
-						},
-						{
-							Name: "lvmcache",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: pointer.SafeDeref(csidriverlvmConfig.HostWritePath) + "/cache",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-						{
-							Name: "lvmarchive",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: pointer.SafeDeref(csidriverlvmConfig.HostWritePath) + "/archive",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-						{
-							Name: "lvmbackup",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: pointer.SafeDeref(csidriverlvmConfig.HostWritePath) + "/backup",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-						{
-							Name: "lvmlock",
-							VolumeSource: corev1.VolumeSource{
-								HostPath: &corev1.HostPathVolumeSource{
-									Path: pointer.SafeDeref(csidriverlvmConfig.HostWritePath) + "/lock",
-									Type: &hostPathTypeCreate,
-								},
-							},
-						},
-					},
-				},
-			},
-		},
+// checkOldCsiLvm returns true if the old csi-lvm is existing in the shoot, the shoot is only probed until the absence
+// of the old csi-lvm is recorded in the status or if a re-check is requested by the annotation of the extension
+func (a *actuator) checkOldCsiLvm(ctx context.Context, ex *extensionsv1alpha1.Extension, shootClient client.Client, status *v1alpha1.CsiDriverLvmStatus) (bool, error) {
+	recheck := ex.Annotations[v1alpha1.RecheckOldCsiLvmAnnotation] == "true"
+	if status.OldCsiLvmAbsent && !recheck {
+		return false, nil
 	}
 
-	objects := []client.Object{
-		csidriverlvmDriver,
-		csidriverlvmServiceAccountPlugin,
-		csidriverlvmClusterRolePlugin,
-		csidriverlvmClusterRoleBindingPlugin,
-		csidriverlvmDefaultStorageClass,
-		csidriverlvmLinearStorageClass,
-		csidriverlvmMirrorStorageClass,
-		csidriverlvmStripedStorageClass,
-		csidriverlvmDaemonSetPlugin,
+	isOldCsiLvmExisting, err := isOldCsiLvmExisting(ctx, shootClient)
+	if err != nil {
+		return false, err
 	}
+	status.OldCsiLvmAbsent = !isOldCsiLvmExisting
 
-	return objects, nil
+	return isOldCsiLvmExisting, nil
 }
 
-func (a *actuator) isOldCsiLvmExisting(ctx context.Context, shootNamespace string) (bool, error) {
-	_, shootClient, err := gutil.NewClientForShoot(ctx, a.client, shootNamespace, client.Options{}, extensionsconfig.RESTOptions{})
+// removeAnnotation removes a one-time annotation like the re-check annotation from the extension, it is only removed
+// after the result has been written to the provider status so that a failed status update applies it again
+func (a *actuator) removeAnnotation(ctx context.Context, ex *extensionsv1alpha1.Extension, annotation string) error {
+	if _, ok := ex.Annotations[annotation]; !ok {
+		return nil
+	}
 
-	if err != nil {
-		return true, fmt.Errorf("failed to create shoot client: %w", err)
+	if err := extensionscontroller.RemoveAnnotation(ctx, a.client, ex, annotation); err != nil {
+		return fmt.Errorf("failed to remove annotation %q: %w", annotation, err)
 	}
+	return nil
+}
 
+// isOldCsiLvmExisting probes the shoot for the namespace and the storage classes of the old csi-lvm
+func isOldCsiLvmExisting(ctx context.Context, shootClient client.Client) (bool, error) {
 	namespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: oldNamespace,
 		},
 	}
-	err = shootClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)
+	err := shootClient.Get(ctx, client.ObjectKeyFromObject(namespace), namespace)
 
 	if err == nil {
 		return true, nil
 	} else if !apierrors.IsNotFound(err) {
-		return true, fmt.Errorf("error while getting old csi-lvm namespace: %w", err)
+		return false, fmt.Errorf("error while getting old csi-lvm namespace: %w", err)
 	}
 
 	storageClassList := &storagev1.StorageClassList{}
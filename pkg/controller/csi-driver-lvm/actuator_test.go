@@ -0,0 +1,367 @@
+package csidriverlvm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	reconcilerutils "github.com/gardener/gardener/pkg/controllerutils/reconciler"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+const testNamespace = "shoot--test--test"
+
+func TestReconcileClusterState(t *testing.T) {
+	worker := gardencorev1beta1.Worker{Name: "default"}
+
+	tt := []struct {
+		desc                string
+		shoot               gardencorev1beta1.Shoot
+		wantConfigProblem   bool
+		wantManagedResource bool
+	}{
+		{
+			desc: "test hibernated shoot",
+			shoot: gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					Hibernation: &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)},
+					Provider:    gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{worker}},
+				},
+				Status: gardencorev1beta1.ShootStatus{IsHibernated: true},
+			},
+			wantManagedResource: true,
+		},
+		{
+			desc: "test hibernating shoot",
+			shoot: gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					Hibernation: &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)},
+					Provider:    gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{worker}},
+				},
+			},
+			wantManagedResource: true,
+		},
+		{
+			desc:              "test workerless shoot",
+			shoot:             gardencorev1beta1.Shoot{},
+			wantConfigProblem: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, extensionscontroller.AddToScheme(scheme))
+			require.NoError(t, resourcesv1alpha1.AddToScheme(scheme))
+			require.NoError(t, install.AddToScheme(scheme))
+
+			shoot := tc.shoot.DeepCopy()
+			shoot.TypeMeta = metav1.TypeMeta{APIVersion: gardencorev1beta1.SchemeGroupVersion.String(), Kind: "Shoot"}
+			rawShoot, err := json.Marshal(shoot)
+			require.NoError(t, err)
+
+			ex := &extensionsv1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: Type},
+			}
+			objects := []client.Object{
+				&extensionsv1alpha1.Cluster{
+					ObjectMeta: metav1.ObjectMeta{Name: testNamespace},
+					Spec:       extensionsv1alpha1.ClusterSpec{Shoot: runtime.RawExtension{Raw: rawShoot}},
+				},
+				ex,
+			}
+			if tc.wantManagedResource {
+				// the managed resource deployed before the hibernation
+				objects = append(objects, &resourcesv1alpha1.ManagedResource{
+					ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmResourceName},
+				})
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(ex).Build()
+			a := &actuator{
+				client:  c,
+				decoder: serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+				config:  config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")},
+			}
+
+			// no shoot kubeconfig exists in the fake client, any shoot access fails the reconciliation
+			err = a.Reconcile(context.Background(), logr.Discard(), ex)
+			if tc.wantConfigProblem {
+				require.Error(t, err)
+				codedErr, ok := err.(v1beta1helper.Coder)
+				require.True(t, ok)
+				assert.Contains(t, codedErr.Codes(), gardencorev1beta1.ErrorConfigurationProblem)
+			} else {
+				require.NoError(t, err)
+			}
+
+			err = c.Get(context.Background(), client.ObjectKey{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmResourceName}, &resourcesv1alpha1.ManagedResource{})
+			if tc.wantManagedResource {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+		})
+	}
+}
+
+func TestCheckOldCsiLvm(t *testing.T) {
+	oldStorageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "csi-lvm"}, Provisioner: oldProvisioner}
+	apiError := interceptor.Funcs{
+		Get: func(ctx context.Context, client client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			return apierrors.NewServiceUnavailable("api server not available")
+		},
+	}
+
+	tt := []struct {
+		desc         string
+		status       v1alpha1.CsiDriverLvmStatus
+		recheck      bool
+		shootObjects []client.Object
+		interceptor  *interceptor.Funcs
+		want         bool
+		wantErr      bool
+		wantAbsent   bool
+	}{
+		{
+			desc:       "test old csi-lvm absent",
+			wantAbsent: true,
+		},
+		{
+			desc:         "test old csi-lvm existing",
+			shootObjects: []client.Object{oldStorageClass},
+			want:         true,
+		},
+		{
+			desc:         "test recorded absence skips the probe",
+			status:       v1alpha1.CsiDriverLvmStatus{OldCsiLvmAbsent: true},
+			shootObjects: []client.Object{oldStorageClass},
+			interceptor:  &apiError,
+			wantAbsent:   true,
+		},
+		{
+			desc:         "test re-check annotation probes again",
+			status:       v1alpha1.CsiDriverLvmStatus{OldCsiLvmAbsent: true},
+			recheck:      true,
+			shootObjects: []client.Object{oldStorageClass},
+			want:         true,
+		},
+		{
+			desc:        "test api error is not treated as existing",
+			interceptor: &apiError,
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ex := &extensionsv1alpha1.Extension{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: Type},
+			}
+			if tc.recheck {
+				ex.Annotations = map[string]string{v1alpha1.RecheckOldCsiLvmAnnotation: "true"}
+			}
+
+			shootClientBuilder := fake.NewClientBuilder().WithObjects(tc.shootObjects...)
+			if tc.interceptor != nil {
+				shootClientBuilder = shootClientBuilder.WithInterceptorFuncs(*tc.interceptor)
+			}
+
+			a := &actuator{}
+
+			status := tc.status.DeepCopy()
+			got, err := a.checkOldCsiLvm(context.Background(), ex, shootClientBuilder.Build(), status)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantAbsent, status.OldCsiLvmAbsent)
+		})
+	}
+}
+
+func TestReconcileOldCsiLvm(t *testing.T) {
+	oldStorageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "csi-lvm"}, Provisioner: oldProvisioner}
+	getError := func(err error) *interceptor.Funcs {
+		return &interceptor.Funcs{
+			Get: func(ctx context.Context, client client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				return err
+			},
+		}
+	}
+
+	tt := []struct {
+		desc                 string
+		shootObjects         []client.Object
+		interceptor          *interceptor.Funcs
+		wantErr              bool
+		wantManagedResources bool
+		wantAbsent           bool
+		wantClients          int
+	}{
+		{
+			desc:                 "test old csi-lvm absent",
+			wantManagedResources: true,
+			wantAbsent:           true,
+			wantClients:          1,
+		},
+		{
+			desc:         "test old csi-lvm existing",
+			shootObjects: []client.Object{oldStorageClass},
+			wantClients:  1,
+		},
+		{
+			desc:        "test shoot api server not available",
+			interceptor: getError(apierrors.NewServiceUnavailable("api server not available")),
+			wantErr:     true,
+			wantClients: 1,
+		},
+		{
+			desc:        "test revoked shoot credentials",
+			interceptor: getError(apierrors.NewUnauthorized("token revoked")),
+			wantErr:     true,
+			// the cached client is replaced in the next reconciliation
+			wantClients: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			a, c := newTestActuator(t, testNamespace)
+
+			clients := 0
+			a.shootClients.newClient = func(secret *corev1.Secret) (client.Client, *http.Client, error) {
+				clients++
+				shootClientBuilder := fake.NewClientBuilder().WithObjects(tc.shootObjects...)
+				if tc.interceptor != nil {
+					shootClientBuilder = shootClientBuilder.WithInterceptorFuncs(*tc.interceptor)
+				}
+				return shootClientBuilder.Build(), nil, nil
+			}
+
+			for range 2 {
+				ex := &extensionsv1alpha1.Extension{}
+				require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+
+				err := a.Reconcile(ctx, logr.Discard(), ex)
+				if tc.wantErr {
+					require.Error(t, err)
+					requeueErr := &reconcilerutils.RequeueAfterError{}
+					require.ErrorAs(t, err, &requeueErr)
+					assert.Equal(t, oldCsiLvmCheckRequeueInterval, requeueErr.RequeueAfter)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+			assert.Equal(t, tc.wantClients, clients)
+
+			for _, name := range shootComponentNames {
+				err := c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: name}, &resourcesv1alpha1.ManagedResource{})
+				if tc.wantManagedResources {
+					assert.NoError(t, err, name)
+				} else {
+					assert.True(t, apierrors.IsNotFound(err), name)
+				}
+			}
+
+			ex := &extensionsv1alpha1.Extension{}
+			require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+			status, err := a.providerStatus(ex)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAbsent, status.OldCsiLvmAbsent)
+
+			// the deletion does not depend on the old csi-lvm
+			require.NoError(t, a.Delete(ctx, logr.Discard(), ex))
+			for _, name := range shootComponentNames {
+				assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: name}, &resourcesv1alpha1.ManagedResource{})), name)
+			}
+		})
+	}
+}
+
+func TestReconcileRecheckOldCsiLvm(t *testing.T) {
+	tt := []struct {
+		desc           string
+		statusErr      error
+		wantErr        bool
+		wantAnnotation bool
+		wantAbsent     bool
+	}{
+		{
+			desc:       "test annotation removed after the status update",
+			wantAbsent: true,
+		},
+		{
+			desc:           "test annotation kept if the status update fails",
+			statusErr:      apierrors.NewConflict(schema.GroupResource{Resource: "extensions"}, Type, errors.New("object was modified")),
+			wantErr:        true,
+			wantAnnotation: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			a, c := newTestActuator(t, testNamespace)
+			a.client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, client client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					if tc.statusErr != nil {
+						return tc.statusErr
+					}
+					return client.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+				},
+			})
+
+			ex := &extensionsv1alpha1.Extension{}
+			require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+			ex.Annotations = map[string]string{v1alpha1.RecheckOldCsiLvmAnnotation: "true"}
+			require.NoError(t, c.Update(ctx, ex))
+
+			err := a.Reconcile(ctx, logr.Discard(), ex)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			current := &extensionsv1alpha1.Extension{}
+			require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(ex), current))
+			if tc.wantAnnotation {
+				assert.Contains(t, current.Annotations, v1alpha1.RecheckOldCsiLvmAnnotation)
+			} else {
+				assert.NotContains(t, current.Annotations, v1alpha1.RecheckOldCsiLvmAnnotation)
+			}
+
+			status, err := a.providerStatus(current)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAbsent, status.OldCsiLvmAbsent)
+		})
+	}
+}
@@ -2,10 +2,15 @@ package csidriverlvm
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/gardener/gardener/extensions/pkg/controller/extension"
+	extensionspredicate "github.com/gardener/gardener/extensions/pkg/predicate"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
 )
@@ -32,6 +37,8 @@ type AddOptions struct {
 	Config config.ControllerConfiguration
 	// IgnoreOperationAnnotation specifies whether to ignore the operation annotation or not.
 	IgnoreOperationAnnotation bool
+	// ShootWebhookConfig contains the configuration of the webhooks in the shoots.
+	ShootWebhookConfig *atomic.Value
 }
 
 // AddToManager adds a controller with the default Options to the given Controller Manager.
@@ -40,15 +47,29 @@ func AddToManager(ctx context.Context, mgr manager.Manager) error {
 }
 
 // AddToManagerWithOptions adds a controller with the given Options to the given manager.
-// The opts.Reconciler is being set with a newly instantiated actuator.
+// The opts.Reconciler is being set with a newly instantiated actuator. The reconciler of gardener is wrapped to requeue
+// extensions with held back image updates at the beginning of the maintenance window of the shoot.
 func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts AddOptions) error {
-	return extension.Add(ctx, mgr, extension.AddArgs{
-		Actuator:          NewActuator(mgr, opts.Config),
+	a := newActuator(mgr, opts.Config, opts.ShootWebhookConfig)
+
+	args := extension.AddArgs{
+		Actuator:          a,
 		ControllerOptions: opts.ControllerOptions,
 		Name:              ControllerName,
 		FinalizerSuffix:   FinalizerSuffix,
 		Resync:            0,
 		Predicates:        extension.DefaultPredicates(ctx, mgr, DefaultAddOptions.IgnoreOperationAnnotation),
 		Type:              Type,
-	})
+	}
+	args.ControllerOptions.Reconciler = &requeueReconciler{
+		Reconciler: extension.NewReconciler(mgr, args),
+		requeues:   a.requeues,
+	}
+
+	ctrl, err := controller.New(args.Name, mgr, args.ControllerOptions)
+	if err != nil {
+		return err
+	}
+
+	return ctrl.Watch(source.Kind(mgr.GetCache(), &extensionsv1alpha1.Extension{}), &handler.EnqueueRequestForObject{}, extensionspredicate.AddTypePredicate(args.Predicates, args.Type)...)
 }
@@ -0,0 +1,193 @@
+package csidriverlvm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gardener/gardener/pkg/chartrenderer"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/charts"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+// shootChartComponents maps the template directories of the shoot chart to the managed resources of the components
+var shootChartComponents = map[string]string{
+	"plugin":         v1alpha1.ShootCsiDriverLvmPluginResourceName,
+	"controller":     v1alpha1.ShootCsiDriverLvmControllerResourceName,
+	"storageclasses": v1alpha1.ShootCsiDriverLvmStorageClassesResourceName,
+}
+
+// shootChartChecksum returns the checksum of the templates and values of the shoot chart, it is part of the hash of the
+// render inputs so that changes of the chart are rolled out even if the version of the extension is not set
+var shootChartChecksum = sync.OnceValues(func() (string, error) {
+	hash := sha256.New()
+	err := fs.WalkDir(charts.InternalChart, charts.ChartPathShootCsiDriverLvm, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(charts.InternalChart, name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(hash, "%s\n%d\n", name, len(content))
+		hash.Write(content)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute checksum of shoot chart: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+})
+
+// newChartRenderer returns the renderer of the shoot chart, the chart does not depend on the version of the shoot
+func newChartRenderer() chartrenderer.Interface {
+	return chartrenderer.NewWithServerVersion(&version.Info{})
+}
+
+// renderShootChart renders the chart of the objects deployed into the shoot and returns the objects by the name of
+// the managed resource of their component
+func (a *actuator) renderShootChart(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, groups []pluginGroup, images map[string]string, highAvailability, multiZonal bool, encryptionChecksums map[string]string) (map[string][]client.Object, error) {
+	values := shootChartValues(csidriverlvmConfig, groups, images, highAvailability, multiZonal, encryptionChecksums)
+
+	release, err := a.chartRenderer.RenderEmbeddedFS(charts.InternalChart, charts.ChartPathShootCsiDriverLvm, "csi-driver-lvm", shootNamespace, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render shoot chart: %w", err)
+	}
+
+	objects := map[string][]client.Object{}
+	for _, manifest := range release.Manifests {
+		if strings.TrimSpace(manifest.Content) == "" {
+			continue
+		}
+
+		// the manifests are named <chart>/templates/<component>/<file>
+		component, ok := shootChartComponents[path.Base(path.Dir(manifest.Name))]
+		if !ok {
+			return nil, fmt.Errorf("template %s of the shoot chart does not belong to a component", manifest.Name)
+		}
+
+		obj, _, err := kubernetes.ShootCodec.UniversalDeserializer().Decode([]byte(manifest.Content), nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s of the shoot chart: %w", manifest.Name, err)
+		}
+		object, ok := obj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object %T in %s of the shoot chart", obj, manifest.Name)
+		}
+
+		objects[component] = append(objects[component], object)
+	}
+
+	return objects, nil
+}
+
+// shootChartValues computes the values of the shoot chart from the defaulted configuration of the shoot, the encrypted
+// storage classes reference the secrets of the keys with the given checksums
+func shootChartValues(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, groups []pluginGroup, images map[string]string, highAvailability, multiZonal bool, encryptionChecksums map[string]string) map[string]any {
+	// the controller is co-located with the plugin, it uses the socket directory of the first plugin group
+	controller := groups[0]
+
+	pluginGroups := []map[string]any{}
+	for _, group := range groups {
+		pluginGroups = append(pluginGroups, map[string]any{
+			"name":            group.name,
+			"hostWritePath":   group.hostWritePath,
+			"kubeletRootDir":  group.paths.kubeletRootDir,
+			"pluginDir":       group.paths.pluginDir,
+			"registrationDir": group.paths.registrationDir,
+			"devDir":          group.paths.devDir,
+			"modulesDir":      group.paths.modulesDir,
+			"nodeAffinity":    group.nodeAffinity(),
+		})
+	}
+
+	plugin := map[string]any{
+		"devicePattern":  pointer.SafeDeref(csidriverlvmConfig.DevicePattern),
+		"updateStrategy": pluginUpdateStrategy(csidriverlvmConfig),
+		"kernelModules":  KernelModules(csidriverlvmConfig),
+		"groups":         pluginGroups,
+	}
+	if conf := lvmConf(csidriverlvmConfig); conf != "" {
+		plugin["lvmConfig"] = conf
+		plugin["lvmConfigChecksum"] = utils.ComputeConfigMapChecksum(map[string]string{LvmConfigKey: conf})
+	}
+
+	storageClasses := []map[string]any{}
+	for _, sc := range mergedStorageClasses(csidriverlvmConfig) {
+		storageClasses = append(storageClasses, map[string]any{
+			"name":          sc.Name,
+			"default":       sc.Name == pointer.SafeDeref(csidriverlvmConfig.DefaultStorageClass),
+			"reclaimPolicy": reclaimPolicy(csidriverlvmConfig),
+			"mountOptions":  sc.MountOptions,
+			"parameters":    storageClassParameters(sc, encryptionChecksums[sc.Name]),
+		})
+	}
+
+	return map[string]any{
+		"images":     images,
+		"pullPolicy": pullPolicy,
+		"logLevel":   logLevel(csidriverlvmConfig),
+		"controller": map[string]any{
+			"pluginDir":    controller.paths.pluginDir,
+			"nodeAffinity": controller.nodeAffinity(),
+			"podAffinity":  controller.podAffinity(),
+			"highAvailability": map[string]any{
+				"enabled":    highAvailability,
+				"replicas":   highAvailabilityReplicas,
+				"multiZonal": multiZonal,
+			},
+		},
+		"plugin":         plugin,
+		"storageClasses": storageClasses,
+	}
+}
+
+// storageClassParameters returns the parameters of the storage class passed to the driver, encrypted storage classes
+// reference the secret of the key with the given checksum
+func storageClassParameters(sc v1alpha1.StorageClass, encryptionChecksum string) map[string]string {
+	parameters := map[string]string{
+		"type": pointer.SafeDeref(sc.Type),
+	}
+	if sc.FsType != nil {
+		parameters[fsTypeParameter] = *sc.FsType
+	}
+	if sc.Stripes != nil {
+		parameters[stripesParameter] = strconv.Itoa(int(*sc.Stripes))
+	}
+	if sc.StripeSize != nil {
+		// lvm expects the stripe size in KiB
+		parameters[stripeSizeParameter] = strconv.FormatInt(sc.StripeSize.Value()/1024, 10) + "k"
+	}
+	if sc.Mirrors != nil {
+		parameters[mirrorsParameter] = strconv.Itoa(int(*sc.Mirrors))
+	}
+	if sc.ThinPool != nil && sc.ThinPool.SizePercentage != nil {
+		parameters[thinPoolSizeParameter] = strconv.Itoa(int(*sc.ThinPool.SizePercentage)) + "%VG"
+	}
+	if sc.ThinPool != nil && sc.ThinPool.OvercommitRatio != nil {
+		parameters[overcommitRatioParameter] = strconv.Itoa(int(*sc.ThinPool.OvercommitRatio))
+	}
+	if sc.Encryption != nil {
+		parameters[encryptionParameter] = "luks"
+		parameters[provisionerSecretNameParameter] = encryptionSecretName(sc.Name, encryptionChecksum)
+		parameters[provisionerSecretNamespaceParameter] = shootNamespace
+		parameters[nodeStageSecretNameParameter] = encryptionSecretName(sc.Name, encryptionChecksum)
+		parameters[nodeStageSecretNamespaceParameter] = shootNamespace
+	}
+
+	return parameters
+}
@@ -0,0 +1,300 @@
+package csidriverlvm
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	driverName string = "lvm.csi.metal-stack.io"
+
+	encryptionName          string = "csi-driver-lvm-encryption"
+	encryptionPassphraseKey string = "passphrase"
+
+	provisionerSecretNameParameter      string = "csi.storage.k8s.io/provisioner-secret-name"
+	provisionerSecretNamespaceParameter string = "csi.storage.k8s.io/provisioner-secret-namespace"
+	nodeStageSecretNameParameter        string = "csi.storage.k8s.io/node-stage-secret-name"
+	nodeStageSecretNamespaceParameter   string = "csi.storage.k8s.io/node-stage-secret-namespace"
+	encryptionParameter                 string = "encryption"
+
+	provisionerDeletionSecretNameAnnotation      string = "volume.kubernetes.io/provisioner-deletion-secret-name"
+	provisionerDeletionSecretNamespaceAnnotation string = "volume.kubernetes.io/provisioner-deletion-secret-namespace"
+
+	// encryptionChecksumLength is the length of the checksum prefix in the names of the encryption secrets
+	encryptionChecksumLength = 10
+
+	// encryptionChecksumKeyName is the name of the secret in the seed containing the key of the checksums of the
+	// encryption keys, the checksums are visible in the shoot and in the status of the extension
+	encryptionChecksumKeyName string = "csi-driver-lvm-encryption-checksum-key"
+	encryptionChecksumKeyKey  string = "key"
+	encryptionChecksumKeySize        = 32
+)
+
+// encryptionSecretName returns the name of the secret in the shoot containing the encryption key with the given checksum,
+// every key gets its own secret as the volumes keep referencing the secret of the key they were created with
+func encryptionSecretName(storageClassName, checksum string) string {
+	return encryptionName + "-" + storageClassName + "-" + checksum[:encryptionChecksumLength]
+}
+
+// encryptionChecksum returns the checksum of the passphrase keyed with the checksum key of the seed, an unkeyed
+// checksum of the passphrase would allow to guess it offline
+func encryptionChecksum(checksumKey, passphrase []byte) string {
+	mac := hmac.New(sha256.New, checksumKey)
+	mac.Write(passphrase)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// isEncrypted returns true if any of the storage classes is encrypted
+func isEncrypted(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) bool {
+	return slices.ContainsFunc(mergedStorageClasses(csidriverlvmConfig), func(sc v1alpha1.StorageClass) bool {
+		return sc.Encryption != nil
+	})
+}
+
+// encryptionChecksumKey returns the key of the checksums of the encryption keys from the seed, it is generated once
+// for every shoot. A new key, e.g. after the control plane was migrated to another seed, only rotates the secrets of
+// the encryption keys in the shoot, the previous secrets are kept while they are in use.
+func (a *actuator) encryptionChecksumKey(ctx context.Context, namespace string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: encryptionChecksumKeyName}, secret)
+	if err == nil {
+		return secret.Data[encryptionChecksumKeyKey], nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get encryption checksum key: %w", err)
+	}
+
+	key := make([]byte, encryptionChecksumKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption checksum key: %w", err)
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      encryptionChecksumKeyName,
+			Namespace: namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{encryptionChecksumKeyKey: key},
+	}
+	if err := a.client.Create(ctx, secret); err != nil {
+		return nil, fmt.Errorf("failed to create encryption checksum key: %w", err)
+	}
+
+	return key, nil
+}
+
+// encryptionSecrets copies the encryption keys referenced by the storage classes from the seed into secrets of the shoot.
+// It also returns the checksums of the keys keyed with the given checksum key by storage class name.
+func encryptionSecrets(ctx context.Context, reader client.Reader, namespace string, cluster *extensionscontroller.Cluster, csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, checksumKey []byte) ([]client.Object, map[string]string, error) {
+	var (
+		secrets   = []client.Object{}
+		checksums = map[string]string{}
+	)
+
+	for _, sc := range mergedStorageClasses(csidriverlvmConfig) {
+		if sc.Encryption == nil {
+			continue
+		}
+
+		if cluster == nil || cluster.Shoot == nil {
+			return nil, nil, fmt.Errorf("unable to resolve the encryption secret of storage class %q without shoot", sc.Name)
+		}
+
+		resource := v1beta1helper.GetResourceByName(cluster.Shoot.Spec.Resources, sc.Encryption.SecretResourceName)
+		if resource == nil {
+			return nil, nil, fmt.Errorf("resource %q referenced by storage class %q not found", sc.Encryption.SecretResourceName, sc.Name)
+		}
+
+		seedSecret := &corev1.Secret{}
+		err := reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: v1beta1constants.ReferencedResourcesPrefix + resource.ResourceRef.Name}, seedSecret)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get encryption secret of storage class %q: %w", sc.Name, err)
+		}
+
+		passphrase, ok := seedSecret.Data[encryptionPassphraseKey]
+		if !ok || len(passphrase) == 0 {
+			return nil, nil, fmt.Errorf("encryption secret of storage class %q does not contain the key %q", sc.Name, encryptionPassphraseKey)
+		}
+
+		data := map[string][]byte{encryptionPassphraseKey: passphrase}
+		checksum := encryptionChecksum(checksumKey, passphrase)
+
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      encryptionSecretName(sc.Name, checksum),
+				Namespace: shootNamespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: data,
+		})
+		checksums[sc.Name] = checksum
+	}
+
+	return secrets, checksums, nil
+}
+
+// retainEncryptionKeys returns the state of the encryption keys with the given checksums. The rotation time of a key is
+// updated whenever its checksum differs from the previous state. Previous keys of a storage class are kept as long as
+// volumes in the shoot reference their secrets, the secrets are read from the shoot as the seed only contains the
+// current keys.
+func retainEncryptionKeys(ctx context.Context, shootClient client.Client, previous []v1alpha1.EncryptionKeyStatus, checksums map[string]string, now metav1.Time) ([]v1alpha1.EncryptionKeyStatus, []client.Object, error) {
+	var (
+		keys     = []v1alpha1.EncryptionKeyStatus{}
+		retained = []client.Object{}
+		inUse    sets.Set[string]
+	)
+
+	for storageClass, checksum := range checksums {
+		if !slices.ContainsFunc(previous, func(key v1alpha1.EncryptionKeyStatus) bool { return key.StorageClass == storageClass }) {
+			keys = append(keys, v1alpha1.EncryptionKeyStatus{StorageClass: storageClass, Checksum: checksum})
+		}
+	}
+
+	for _, previousKey := range previous {
+		key := v1alpha1.EncryptionKeyStatus{
+			StorageClass:     previousKey.StorageClass,
+			Checksum:         checksums[previousKey.StorageClass],
+			LastRotationTime: previousKey.LastRotationTime,
+		}
+		if key.Checksum != "" && key.Checksum != previousKey.Checksum {
+			key.LastRotationTime = &now
+		}
+
+		for _, checksum := range append(slices.Clone(previousKey.PreviousChecksums), previousKey.Checksum) {
+			if checksum == "" || checksum == key.Checksum || slices.Contains(key.PreviousChecksums, checksum) {
+				continue
+			}
+
+			if inUse == nil {
+				var err error
+				inUse, err = encryptionSecretsInUse(ctx, shootClient)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			name := encryptionSecretName(key.StorageClass, checksum)
+			if !inUse.Has(name) {
+				continue
+			}
+
+			secret := &corev1.Secret{}
+			if err := shootClient.Get(ctx, client.ObjectKey{Namespace: shootNamespace, Name: name}, secret); err != nil {
+				return nil, nil, fmt.Errorf("failed to get previous encryption key of storage class %q which is still used by volumes: %w", key.StorageClass, err)
+			}
+
+			retained = append(retained, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: shootNamespace,
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: secret.Data,
+			})
+			key.PreviousChecksums = append(key.PreviousChecksums, checksum)
+		}
+
+		if key.Checksum == "" && len(key.PreviousChecksums) == 0 {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b v1alpha1.EncryptionKeyStatus) int {
+		return strings.Compare(a.StorageClass, b.StorageClass)
+	})
+
+	return keys, retained, nil
+}
+
+// encryptionSecretsInUse returns the names of the encryption secrets referenced by the persistent volumes of the driver
+func encryptionSecretsInUse(ctx context.Context, shootClient client.Client) (sets.Set[string], error) {
+	volumes := &corev1.PersistentVolumeList{}
+	if err := shootClient.List(ctx, volumes); err != nil {
+		return nil, fmt.Errorf("failed to list persistent volumes of the shoot: %w", err)
+	}
+
+	names := sets.New[string]()
+	for _, volume := range volumes.Items {
+		if volume.Spec.CSI == nil || volume.Spec.CSI.Driver != driverName {
+			continue
+		}
+		if ref := volume.Spec.CSI.NodeStageSecretRef; ref != nil && ref.Namespace == shootNamespace {
+			names.Insert(ref.Name)
+		}
+		// the provisioner reads the secret for the deletion of the volume from the annotations of the volume
+		if volume.Annotations[provisionerDeletionSecretNamespaceAnnotation] == shootNamespace {
+			names.Insert(volume.Annotations[provisionerDeletionSecretNameAnnotation])
+		}
+	}
+
+	return names, nil
+}
+
+// encryptionObjects returns the given encryption secrets together with the role allowing the controller to read them
+func encryptionObjects(secrets []client.Object) []client.Object {
+	secretNames := []string{}
+	for _, secret := range secrets {
+		secretNames = append(secretNames, secret.GetName())
+	}
+	objects := slices.Clone(secrets)
+
+	if len(secretNames) == 0 {
+		return objects
+	}
+
+	encryptionRole := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      encryptionName,
+			Namespace: shootNamespace,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets"},
+				ResourceNames: secretNames,
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+
+	encryptionRoleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      encryptionName,
+			Namespace: shootNamespace,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      "csi-driver-lvm-controller",
+				Namespace: shootNamespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     encryptionName,
+		},
+	}
+
+	return append(objects, encryptionRole, encryptionRoleBinding)
+}
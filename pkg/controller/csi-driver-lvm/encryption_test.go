@@ -0,0 +1,282 @@
+package csidriverlvm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+func TestEncryptionObjects(t *testing.T) {
+	cluster := &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{
+		Resources: []gardencorev1beta1.NamedResourceReference{
+			{Name: "luks", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "luks-key", APIVersion: "v1"}},
+			{Name: "luks-missing", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "missing", APIVersion: "v1"}},
+			{Name: "luks-without-key", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "without-key", APIVersion: "v1"}},
+			{Name: "luks-empty", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "empty", APIVersion: "v1"}},
+		},
+	}}}
+	seedSecret := func(name string, data map[string][]byte) client.Object {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "ref-" + name}, Data: data}
+	}
+	encrypted := func(name, resource string) v1alpha1.StorageClass {
+		return v1alpha1.StorageClass{Name: name, Type: ptr.To(v1alpha1.VolumeTypeLinear), Encryption: &v1alpha1.Encryption{SecretResourceName: resource}}
+	}
+
+	reader := fake.NewClientBuilder().WithScheme(kubernetes.SeedScheme).WithObjects(
+		seedSecret("luks-key", map[string][]byte{encryptionPassphraseKey: []byte("secret")}),
+		seedSecret("without-key", map[string][]byte{"key": []byte("secret")}),
+		seedSecret("empty", map[string][]byte{encryptionPassphraseKey: {}}),
+	).Build()
+
+	checksumKey := []byte("checksum-key")
+	checksum := encryptionChecksum(checksumKey, []byte("secret"))
+
+	tt := []struct {
+		desc            string
+		storageClasses  []v1alpha1.StorageClass
+		cluster         *extensionscontroller.Cluster
+		wantSecretNames []string
+		wantErr         string
+	}{
+		{
+			desc:    "test without encrypted storage classes",
+			cluster: cluster,
+		},
+		{
+			desc:            "test encrypted storage classes",
+			storageClasses:  []v1alpha1.StorageClass{encrypted("encrypted-a", "luks"), encrypted("encrypted-b", "luks")},
+			cluster:         cluster,
+			wantSecretNames: []string{encryptionSecretName("encrypted-a", checksum), encryptionSecretName("encrypted-b", checksum)},
+		},
+		{
+			desc:           "test without shoot",
+			storageClasses: []v1alpha1.StorageClass{encrypted("encrypted", "luks")},
+			wantErr:        `unable to resolve the encryption secret of storage class "encrypted" without shoot`,
+		},
+		{
+			desc:           "test unknown resource",
+			storageClasses: []v1alpha1.StorageClass{encrypted("encrypted", "unknown")},
+			cluster:        cluster,
+			wantErr:        `resource "unknown" referenced by storage class "encrypted" not found`,
+		},
+		{
+			desc:           "test missing secret",
+			storageClasses: []v1alpha1.StorageClass{encrypted("encrypted", "luks-missing")},
+			cluster:        cluster,
+			wantErr:        `failed to get encryption secret of storage class "encrypted"`,
+		},
+		{
+			desc:           "test secret without passphrase",
+			storageClasses: []v1alpha1.StorageClass{encrypted("encrypted", "luks-without-key")},
+			cluster:        cluster,
+			wantErr:        `encryption secret of storage class "encrypted" does not contain the key "passphrase"`,
+		},
+		{
+			desc:           "test secret with empty passphrase",
+			storageClasses: []v1alpha1.StorageClass{encrypted("encrypted", "luks-empty")},
+			cluster:        cluster,
+			wantErr:        `encryption secret of storage class "encrypted" does not contain the key "passphrase"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			secrets, checksums, err := encryptionSecrets(context.Background(), reader, testNamespace, tc.cluster, &v1alpha1.CsiDriverLvmConfig{StorageClasses: tc.storageClasses}, checksumKey)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			objects := encryptionObjects(secrets)
+
+			var (
+				secretNames []string
+				role        *rbacv1.Role
+				roleBinding *rbacv1.RoleBinding
+			)
+			for _, obj := range objects {
+				switch o := obj.(type) {
+				case *corev1.Secret:
+					assert.Equal(t, shootNamespace, o.Namespace)
+					assert.Equal(t, []byte("secret"), o.Data[encryptionPassphraseKey])
+					secretNames = append(secretNames, o.Name)
+				case *rbacv1.Role:
+					role = o
+				case *rbacv1.RoleBinding:
+					roleBinding = o
+				}
+			}
+			assert.Equal(t, tc.wantSecretNames, secretNames)
+			assert.Len(t, checksums, len(tc.wantSecretNames))
+
+			if len(tc.wantSecretNames) == 0 {
+				assert.Nil(t, role)
+				assert.Nil(t, roleBinding)
+				return
+			}
+
+			// the controller may only read the encryption keys of the storage classes
+			require.NotNil(t, role)
+			require.Len(t, role.Rules, 1)
+			assert.Equal(t, tc.wantSecretNames, role.Rules[0].ResourceNames)
+			assert.Equal(t, []string{"get"}, role.Rules[0].Verbs)
+
+			require.NotNil(t, roleBinding)
+			assert.Equal(t, role.Name, roleBinding.RoleRef.Name)
+			assert.Equal(t, []rbacv1.Subject{{Kind: "ServiceAccount", Name: "csi-driver-lvm-controller", Namespace: shootNamespace}}, roleBinding.Subjects)
+		})
+	}
+}
+
+func TestEncryptionObjectsOffline(t *testing.T) {
+	cluster := &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{
+		Resources: []gardencorev1beta1.NamedResourceReference{
+			{Name: "luks", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "luks", APIVersion: "v1"}},
+		},
+	}}}
+
+	secrets, checksums, err := encryptionSecrets(context.Background(), placeholderSecretReader{}, "", cluster, &v1alpha1.CsiDriverLvmConfig{StorageClasses: []v1alpha1.StorageClass{
+		{Name: "encrypted", Type: ptr.To(v1alpha1.VolumeTypeLinear), Encryption: &v1alpha1.Encryption{SecretResourceName: "luks"}},
+	}}, []byte(placeholderChecksumKey))
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+
+	secret, ok := secrets[0].(*corev1.Secret)
+	require.True(t, ok)
+	assert.Equal(t, encryptionSecretName("encrypted", checksums["encrypted"]), secret.Name)
+	assert.Equal(t, []byte(placeholderPassphrase), secret.Data[encryptionPassphraseKey])
+}
+
+func TestEncryptionChecksumKey(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	key, err := a.encryptionChecksumKey(ctx, testNamespace)
+	require.NoError(t, err)
+	assert.Len(t, key, encryptionChecksumKeySize)
+
+	// the key is generated once, otherwise every reconciliation would rotate the secrets of the encryption keys
+	again, err := a.encryptionChecksumKey(ctx, testNamespace)
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	secret := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: encryptionChecksumKeyName}, secret))
+	assert.Equal(t, key, secret.Data[encryptionChecksumKeyKey])
+
+	// the checksum must not allow to guess the passphrase without the key of the seed
+	passphrase := []byte("secret")
+	unkeyed := sha256.Sum256(passphrase)
+	assert.NotEqual(t, hex.EncodeToString(unkeyed[:]), encryptionChecksum(key, passphrase))
+	assert.NotEqual(t, encryptionChecksum([]byte("other"), passphrase), encryptionChecksum(key, passphrase))
+	assert.Equal(t, encryptionChecksum(key, passphrase), encryptionChecksum(again, passphrase))
+}
+
+func TestRetainEncryptionKeys(t *testing.T) {
+	var (
+		rotated = metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		now     = metav1.NewTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	)
+
+	checksum := func(c string) string { return strings.Repeat(c, 64) }
+	volume := func(name, secretName string) client.Object {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: &corev1.CSIPersistentVolumeSource{
+				Driver:             driverName,
+				NodeStageSecretRef: &corev1.SecretReference{Namespace: shootNamespace, Name: secretName},
+			}}},
+		}
+	}
+	shootSecret := func(storageClass, c string) client.Object {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: shootNamespace, Name: encryptionSecretName(storageClass, checksum(c))},
+			Data:       map[string][]byte{encryptionPassphraseKey: []byte(c)},
+		}
+	}
+
+	previous := []v1alpha1.EncryptionKeyStatus{
+		{StorageClass: "unchanged", Checksum: checksum("a"), LastRotationTime: &rotated},
+		{StorageClass: "rotated", Checksum: checksum("b"), PreviousChecksums: []string{checksum("c")}},
+		{StorageClass: "removed", Checksum: checksum("d")},
+		{StorageClass: "removed-unused", Checksum: checksum("e")},
+	}
+	checksums := map[string]string{
+		"unchanged": checksum("a"),
+		"rotated":   checksum("f"),
+		"new":       checksum("0"),
+	}
+
+	shootClient := fake.NewClientBuilder().WithObjects(
+		volume("pv-rotated-b", encryptionSecretName("rotated", checksum("b"))),
+		volume("pv-removed-d", encryptionSecretName("removed", checksum("d"))),
+		shootSecret("rotated", "b"),
+		shootSecret("rotated", "c"),
+		shootSecret("removed", "d"),
+		shootSecret("removed-unused", "e"),
+	).Build()
+
+	keys, retained, err := retainEncryptionKeys(context.Background(), shootClient, previous, checksums, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []v1alpha1.EncryptionKeyStatus{
+		{StorageClass: "new", Checksum: checksum("0")},
+		{StorageClass: "removed", PreviousChecksums: []string{checksum("d")}},
+		{StorageClass: "rotated", Checksum: checksum("f"), LastRotationTime: &now, PreviousChecksums: []string{checksum("b")}},
+		{StorageClass: "unchanged", Checksum: checksum("a"), LastRotationTime: &rotated},
+	}, keys)
+
+	// only the keys still used by volumes are kept in the shoot
+	var secretNames []string
+	for _, obj := range retained {
+		secret, ok := obj.(*corev1.Secret)
+		require.True(t, ok)
+		assert.Equal(t, shootNamespace, secret.Namespace)
+		assert.NotEmpty(t, secret.Data[encryptionPassphraseKey])
+		secretNames = append(secretNames, secret.Name)
+	}
+	assert.ElementsMatch(t, []string{encryptionSecretName("rotated", checksum("b")), encryptionSecretName("removed", checksum("d"))}, secretNames)
+
+	t.Run("test previous key used by volumes but missing in the shoot", func(t *testing.T) {
+		shootClient := fake.NewClientBuilder().WithObjects(volume("pv-rotated-b", encryptionSecretName("rotated", checksum("b")))).Build()
+
+		_, _, err := retainEncryptionKeys(context.Background(), shootClient, previous, checksums, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `failed to get previous encryption key of storage class "rotated" which is still used by volumes`)
+	})
+
+	t.Run("test without previous keys", func(t *testing.T) {
+		// the volumes of the shoot are only listed if there are previous keys
+		shootClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+				return errors.New("unexpected list")
+			},
+		}).Build()
+
+		keys, retained, err := retainEncryptionKeys(context.Background(), shootClient, nil, checksums, now)
+		require.NoError(t, err)
+		assert.Len(t, keys, 3)
+		assert.Empty(t, retained)
+	})
+}
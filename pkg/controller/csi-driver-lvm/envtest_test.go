@@ -0,0 +1,165 @@
+package csidriverlvm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+)
+
+// startTestEnvironment starts an api server for the seed with the CRDs of the gardener resources used by the actuator,
+// the test is skipped if the envtest binaries are not installed. make test installs them and sets KUBEBUILDER_ASSETS.
+func startTestEnvironment(t *testing.T) (client.Client, *runtime.Scheme) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set, install the envtest binaries with setup-envtest to run this test")
+	}
+
+	// the CRDs are copied from the examples of the gardener module the extension is built with by make envtest-crds
+	testEnv := &envtest.Environment{
+		CRDInstallOptions: envtest.CRDInstallOptions{
+			Paths: []string{filepath.Join("testdata", "crds")},
+		},
+		ErrorIfCRDPathMissing: true,
+	}
+	restConfig, err := testEnv.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, testEnv.Stop()) })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, extensionscontroller.AddToScheme(scheme))
+	require.NoError(t, resourcesv1alpha1.AddToScheme(scheme))
+	require.NoError(t, install.AddToScheme(scheme))
+
+	c, err := client.New(restConfig, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+
+	return c, scheme
+}
+
+// createTestShoot creates the namespace of a shoot in the seed with its Cluster, the secret of its shoot client and
+// the Extension
+func createTestShoot(t *testing.T, ctx context.Context, c client.Client, namespace string) {
+	raw := func(obj runtime.Object) runtime.RawExtension {
+		data, err := json.Marshal(obj)
+		require.NoError(t, err)
+		return runtime.RawExtension{Raw: data}
+	}
+	typeMeta := func(kind string) metav1.TypeMeta {
+		return metav1.TypeMeta{APIVersion: gardencorev1beta1.SchemeGroupVersion.String(), Kind: kind}
+	}
+
+	for _, obj := range []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		&extensionsv1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: namespace},
+			Spec: extensionsv1alpha1.ClusterSpec{
+				CloudProfile: raw(&gardencorev1beta1.CloudProfile{TypeMeta: typeMeta("CloudProfile")}),
+				Seed:         raw(&gardencorev1beta1.Seed{TypeMeta: typeMeta("Seed")}),
+				Shoot: raw(&gardencorev1beta1.Shoot{
+					TypeMeta: typeMeta("Shoot"),
+					Spec: gardencorev1beta1.ShootSpec{
+						Provider: gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{{Name: "default"}}},
+					},
+				}),
+			},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: v1beta1constants.SecretNameGardenerInternal}},
+		&extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: Type},
+			Spec:       extensionsv1alpha1.ExtensionSpec{DefaultSpec: extensionsv1alpha1.DefaultSpec{Type: Type}},
+		},
+	} {
+		require.NoError(t, c.Create(ctx, obj))
+	}
+}
+
+func TestEnvtestReconcileAndDelete(t *testing.T) {
+	c, scheme := startTestEnvironment(t)
+
+	tt := []struct {
+		desc                 string
+		namespace            string
+		shootObjects         []client.Object
+		wantManagedResources bool
+		wantAbsent           bool
+	}{
+		{
+			desc:                 "test old csi-lvm absent",
+			namespace:            "shoot--test--absent",
+			wantManagedResources: true,
+			wantAbsent:           true,
+		},
+		{
+			desc:         "test old csi-lvm existing",
+			namespace:    "shoot--test--existing",
+			shootObjects: []client.Object{&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "csi-lvm"}, Provisioner: oldProvisioner}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctx := context.Background()
+			createTestShoot(t, ctx, c, tc.namespace)
+
+			shootClients := newShootClientCache(c)
+			shootClients.newClient = func(secret *corev1.Secret) (client.Client, *http.Client, error) {
+				return fake.NewClientBuilder().WithObjects(tc.shootObjects...).Build(), nil, nil
+			}
+			a := &actuator{
+				client:        c,
+				decoder:       serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+				config:        config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")},
+				shootClients:  shootClients,
+				chartRenderer: newChartRenderer(),
+			}
+
+			ex := &extensionsv1alpha1.Extension{}
+			require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: tc.namespace, Name: Type}, ex))
+			require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+			for _, name := range shootComponentNames {
+				err := c.Get(ctx, client.ObjectKey{Namespace: tc.namespace, Name: name}, &resourcesv1alpha1.ManagedResource{})
+				if tc.wantManagedResources {
+					assert.NoError(t, err, name)
+				} else {
+					assert.True(t, apierrors.IsNotFound(err), name)
+				}
+			}
+
+			// the provider status is written through the status subresource of the api server
+			require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: tc.namespace, Name: Type}, ex))
+			status, err := a.providerStatus(ex)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAbsent, status.OldCsiLvmAbsent)
+
+			require.NoError(t, a.Delete(ctx, logr.Discard(), ex))
+			for _, name := range shootComponentNames {
+				assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: tc.namespace, Name: name}, &resourcesv1alpha1.ManagedResource{})), name)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package csidriverlvm
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/managedresources"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+// update regenerates the golden files, e.g. go test ./pkg/controller/... -run Golden -update
+var update = flag.Bool("update", false, "update the golden files")
+
+// goldenImages are the images used for the golden files, they are fixed so that updates of the image vector do not
+// change the golden files
+var goldenImages = map[string]string{
+	imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+	imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0",
+	imageCsiAttacher:             "registry.k8s.io/sig-storage/csi-attacher:v4.6.1",
+	imageCsiProvisioner:          "registry.k8s.io/sig-storage/csi-provisioner:v5.0.1",
+	imageCsiResizer:              "registry.k8s.io/sig-storage/csi-resizer:v1.11.1",
+	imageCsiNodeDriverRegistrar:  "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.11.1",
+	imageLivenessprobe:           "registry.k8s.io/sig-storage/livenessprobe:v2.13.1",
+}
+
+// goldenVariant is a configuration for which the objects are compared to the golden files in testdata/<variant>
+type goldenVariant struct {
+	name             string
+	config           *v1alpha1.CsiDriverLvmConfig
+	controllerConfig config.ControllerConfiguration
+	cluster          *extensionscontroller.Cluster
+}
+
+func goldenVariants() []goldenVariant {
+	controllerConfig := config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")}
+	onDelete := appsv1.OnDeleteDaemonSetStrategyType
+
+	defaultPaths := controllerConfig
+	defaultPaths.DefaultPaths = &config.Paths{
+		PluginDir:       ptr.To("/var/data/kubelet/plugins/csi-driver-lvm"),
+		RegistrationDir: ptr.To("/var/data/kubelet/plugins_registry"),
+	}
+
+	driverFeatures := controllerConfig
+	driverFeatures.AllowGeometry = ptr.To(true)
+	driverFeatures.AllowThinAndRaid = ptr.To(true)
+
+	machineImageDefaults := controllerConfig
+	machineImageDefaults.MachineImageDefaults = []config.MachineImageDefaults{
+		{Name: "talos", HostWritePath: ptr.To("/var/etc/lvm"), KubeletRootDir: ptr.To("/var/lib/kubelet-talos")},
+	}
+
+	return []goldenVariant{
+		{
+			name:             "default",
+			controllerConfig: controllerConfig,
+		},
+		{
+			name: "custom",
+			config: &v1alpha1.CsiDriverLvmConfig{
+				DevicePattern:        ptr.To("/dev/sd[b-z]"),
+				HostWritePath:        ptr.To("/var/lvm"),
+				Paths:                &v1alpha1.Paths{KubeletRootDir: ptr.To("/var/lib/k0s/kubelet")},
+				LvmConfig:            &v1alpha1.LvmConfig{DeviceFilter: []string{"a|/dev/sd.*|", "r|.*|"}},
+				PluginUpdateStrategy: &v1alpha1.PluginUpdateStrategy{Type: &onDelete},
+				ReclaimPolicy:        ptr.To(corev1.PersistentVolumeReclaimRetain),
+				LogLevel:             ptr.To(int32(2)),
+				StorageClasses: []v1alpha1.StorageClass{
+					{Name: "csi-driver-lvm-fast", Type: ptr.To(v1alpha1.VolumeTypeStriped), Stripes: ptr.To(int32(2))},
+					{Name: "csi-driver-lvm-thin", Type: ptr.To(v1alpha1.VolumeTypeThin)},
+				},
+				DefaultStorageClass: ptr.To("csi-driver-lvm-thin"),
+			},
+			controllerConfig: driverFeatures,
+		},
+		{
+			name: "development",
+			config: &v1alpha1.CsiDriverLvmConfig{
+				LoopDevices: &v1alpha1.LoopDevices{Count: 2, Size: resource.MustParse("1Gi")},
+			},
+			controllerConfig: controllerConfig,
+			cluster: goldenCluster(gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{
+				Purpose:  ptr.To(gardencorev1beta1.ShootPurposeDevelopment),
+				Provider: gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{{Name: "default"}}},
+			}}),
+		},
+		{
+			name:             "high-availability",
+			controllerConfig: controllerConfig,
+			cluster: goldenCluster(gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{
+				ControlPlane: &gardencorev1beta1.ControlPlane{HighAvailability: &gardencorev1beta1.HighAvailability{
+					FailureTolerance: gardencorev1beta1.FailureTolerance{Type: gardencorev1beta1.FailureToleranceTypeZone},
+				}},
+				Provider: gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{{Name: "default"}}},
+			}}),
+		},
+		{
+			name:             "paths",
+			controllerConfig: defaultPaths,
+		},
+		{
+			name:             "worker-pools",
+			controllerConfig: machineImageDefaults,
+			cluster: goldenCluster(gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{
+				Provider: gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{
+					{Name: "default", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "debian"}}},
+					{Name: "talos", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "talos"}}},
+				}},
+			}}),
+		},
+	}
+}
+
+func goldenCluster(shoot gardencorev1beta1.Shoot) *extensionscontroller.Cluster {
+	return &extensionscontroller.Cluster{Shoot: &shoot}
+}
+
+// TestGoldenShootChart compares the objects rendered from the shoot chart with the golden files of the components, the
+// golden files were recorded from the objects built in Go before the chart was introduced
+func TestGoldenShootChart(t *testing.T) {
+	files := map[string]string{
+		v1alpha1.ShootCsiDriverLvmControllerResourceName:     "controller.yaml",
+		v1alpha1.ShootCsiDriverLvmPluginResourceName:         "plugin.yaml",
+		v1alpha1.ShootCsiDriverLvmStorageClassesResourceName: "storageclasses.yaml",
+	}
+
+	for _, variant := range goldenVariants() {
+		t.Run(variant.name, func(t *testing.T) {
+			a, csidriverlvmConfig, groups := prepareGoldenVariant(t, variant)
+
+			objects, err := a.renderShootChart(csidriverlvmConfig, groups, goldenImages, isHighAvailability(csidriverlvmConfig, variant.cluster), isMultiZonal(variant.cluster), nil)
+			require.NoError(t, err)
+
+			for name, file := range files {
+				assertGolden(t, filepath.Join("testdata", variant.name, file), objects[name])
+			}
+		})
+	}
+}
+
+// prepareGoldenVariant defaults and validates the configuration of the variant the same way as the actuator
+func prepareGoldenVariant(t *testing.T, variant goldenVariant) (*actuator, *v1alpha1.CsiDriverLvmConfig, []pluginGroup) {
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	if variant.config != nil {
+		csidriverlvmConfig = variant.config.DeepCopy()
+	}
+
+	workerPools, err := prepareConfig(logr.Discard(), csidriverlvmConfig, variant.controllerConfig, variant.cluster)
+	require.NoError(t, err)
+
+	return &actuator{config: variant.controllerConfig, chartRenderer: newChartRenderer()}, csidriverlvmConfig, pluginGroups(csidriverlvmConfig, variant.controllerConfig, workerPools)
+}
+
+// assertGolden compares the objects serialized like in the managed resources with the golden file, the golden file is
+// written instead if the update flag is set
+func assertGolden(t *testing.T, path string, objects []client.Object) {
+	data, err := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer).AddAllAndSerialize(objects...)
+	require.NoError(t, err)
+
+	got := &bytes.Buffer{}
+	for _, name := range slices.Sorted(maps.Keys(data)) {
+		fmt.Fprintf(got, "---\n# Source: %s\n%s", name, data[name])
+	}
+
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, got.Bytes(), 0o644))
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file is missing, run the tests with -update to create it")
+	assert.Equal(t, string(want), got.String(), "objects differ from %s, run the tests with -update if the change is intended", path)
+}
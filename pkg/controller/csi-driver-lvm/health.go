@@ -0,0 +1,125 @@
+package csidriverlvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils/kubernetes/health"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// healthCheckInterval is the interval in which the health of the managed resources is checked while waiting
+var healthCheckInterval = 5 * time.Second
+
+// waitUntilHealthy waits until the managed resources of the components are applied and healthy. If they do not become
+// healthy within the timeout, the returned error contains the reasons of the pods of the components in the shoot.
+func (a *actuator) waitUntilHealthy(ctx context.Context, namespace string, shootClient client.Client, groups []pluginGroup, timeout time.Duration) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var unhealthy error
+	_ = wait.PollUntilContextCancel(timeoutCtx, healthCheckInterval, true, func(ctx context.Context) (bool, error) {
+		unhealthy = a.checkManagedResources(ctx, namespace)
+		return unhealthy == nil, nil
+	})
+	if unhealthy == nil {
+		return nil
+	}
+
+	apps := []string{controllerName}
+	for _, group := range groups {
+		apps = append(apps, group.name)
+	}
+
+	reasons, err := podReasons(ctx, shootClient, apps)
+	if err != nil {
+		reasons = []string{err.Error()}
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("managed resources did not become healthy within %s: %w", timeout, unhealthy)
+	}
+
+	return fmt.Errorf("managed resources did not become healthy within %s: %w, pods: %s", timeout, unhealthy, strings.Join(reasons, "; "))
+}
+
+// checkManagedResources returns an error for every managed resource of the components which is not applied and healthy
+func (a *actuator) checkManagedResources(ctx context.Context, namespace string) error {
+	var errs []error
+	for _, name := range shootComponentNames {
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		if err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, managedResource); err != nil {
+			errs = append(errs, fmt.Errorf("failed to get managed resource %q: %w", name, err))
+			continue
+		}
+
+		if err := health.CheckManagedResource(managedResource); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// podReasons returns why the pods of the given apps in the shoot are not ready, equal reasons of multiple pods are
+// only reported once
+func podReasons(ctx context.Context, shootClient client.Client, apps []string) ([]string, error) {
+	pods := &corev1.PodList{}
+	if err := shootClient.List(ctx, pods, client.InNamespace(shootNamespace)); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	reasons := []string{}
+	for _, pod := range pods.Items {
+		if !slices.Contains(apps, pod.Labels["app"]) {
+			continue
+		}
+
+		for _, reason := range podReason(&pod) {
+			reason = pod.Labels["app"] + ": " + reason
+			if !slices.Contains(reasons, reason) {
+				reasons = append(reasons, reason)
+			}
+		}
+	}
+
+	slices.Sort(reasons)
+	return reasons, nil
+}
+
+// podReason returns the reasons of the containers of the pod which are not ready, a pod which cannot be scheduled is
+// reported with the reason of the scheduler
+func podReason(pod *corev1.Pod) []string {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+			return []string{fmt.Sprintf("pod is not scheduled: %s: %s", condition.Reason, condition.Message)}
+		}
+	}
+
+	reasons := []string{}
+	for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "":
+			reasons = append(reasons, containerReason(status.Name, status.State.Waiting.Reason, status.State.Waiting.Message))
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+			reasons = append(reasons, containerReason(status.Name, status.State.Terminated.Reason, status.State.Terminated.Message))
+		case !status.Ready && status.LastTerminationState.Terminated != nil:
+			reasons = append(reasons, containerReason(status.Name, status.LastTerminationState.Terminated.Reason, status.LastTerminationState.Terminated.Message))
+		}
+	}
+
+	return reasons
+}
+
+func containerReason(container, reason, message string) string {
+	if message == "" {
+		return fmt.Sprintf("container %s: %s", container, reason)
+	}
+	return fmt.Sprintf("container %s: %s: %s", container, reason, message)
+}
@@ -0,0 +1,143 @@
+package csidriverlvm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodReason(t *testing.T) {
+	tt := []struct {
+		desc string
+		pod  corev1.Pod
+		want []string
+	}{
+		{
+			desc: "test ready pod",
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "csi-driver-lvm-plugin", Ready: true, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+			}}},
+			want: []string{},
+		},
+		{
+			desc: "test unschedulable pod",
+			pod: corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: "Unschedulable", Message: "0/3 nodes are available"},
+			}}},
+			want: []string{"pod is not scheduled: Unschedulable: 0/3 nodes are available"},
+		},
+		{
+			desc: "test image pull and crash loop",
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "csi-driver-lvm-plugin", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "image not found"}}},
+				{Name: "csi-node-driver-registrar", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			}}},
+			want: []string{
+				"container csi-driver-lvm-plugin: ImagePullBackOff: image not found",
+				"container csi-node-driver-registrar: CrashLoopBackOff",
+			},
+		},
+		{
+			desc: "test restarted container",
+			pod: corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{
+					Name:                 "csi-driver-lvm-plugin",
+					State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+					LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error", Message: "no device matches"}},
+				},
+			}}},
+			want: []string{"container csi-driver-lvm-plugin: Error: no device matches"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, podReason(&tc.pod))
+		})
+	}
+}
+
+func TestWaitUntilHealthy(t *testing.T) {
+	defer func(previous time.Duration) { healthCheckInterval = previous }(healthCheckInterval)
+	healthCheckInterval = time.Millisecond
+
+	managedResources := func(healthy gardencorev1beta1.ConditionStatus) []client.Object {
+		objects := []client.Object{}
+		for _, name := range shootComponentNames {
+			objects = append(objects, &resourcesv1alpha1.ManagedResource{
+				ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+				Status: resourcesv1alpha1.ManagedResourceStatus{Conditions: []gardencorev1beta1.Condition{
+					{Type: resourcesv1alpha1.ResourcesApplied, Status: gardencorev1beta1.ConditionTrue},
+					{Type: resourcesv1alpha1.ResourcesHealthy, Status: healthy, Message: "daemon set is not ready"},
+				}},
+			})
+		}
+		return objects
+	}
+	pods := []client.Object{
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: shootNamespace, Name: "csi-driver-lvm-plugin-a", Labels: map[string]string{"app": "csi-driver-lvm-plugin"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "csi-driver-lvm-plugin", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: shootNamespace, Name: "csi-driver-lvm-plugin-b", Labels: map[string]string{"app": "csi-driver-lvm-plugin"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "csi-driver-lvm-plugin", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: shootNamespace, Name: "other", Labels: map[string]string{"app": "other"}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "other", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}},
+			}},
+		},
+	}
+	groups := []pluginGroup{{name: "csi-driver-lvm-plugin"}}
+
+	tt := []struct {
+		desc    string
+		healthy gardencorev1beta1.ConditionStatus
+		wantErr string
+	}{
+		{
+			desc:    "test healthy managed resources",
+			healthy: gardencorev1beta1.ConditionTrue,
+		},
+		{
+			desc:    "test unhealthy managed resources",
+			healthy: gardencorev1beta1.ConditionFalse,
+			wantErr: "daemon set is not ready, pods: csi-driver-lvm-plugin: container csi-driver-lvm-plugin: ImagePullBackOff",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, resourcesv1alpha1.AddToScheme(scheme))
+
+			a := &actuator{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(managedResources(tc.healthy)...).Build()}
+			shootClient := fake.NewClientBuilder().WithObjects(pods...).Build()
+
+			err := a.waitUntilHealthy(context.Background(), testNamespace, shootClient, groups, 10*time.Millisecond)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.wantErr)
+			assert.NotContains(t, err.Error(), "CrashLoopBackOff")
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package csidriverlvm
+
+import (
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+const (
+	controllerName           string = "csi-driver-lvm-controller"
+	highAvailabilityReplicas int32  = 2
+)
+
+// isHighAvailability returns true if the controller is run highly available, an explicit configuration takes
+// precedence over the failure tolerance of the shoot control plane
+func isHighAvailability(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, cluster *extensionscontroller.Cluster) bool {
+	if csidriverlvmConfig.HighAvailability != nil {
+		return *csidriverlvmConfig.HighAvailability
+	}
+	return cluster != nil && cluster.Shoot != nil && v1beta1helper.IsHAControlPlaneConfigured(cluster.Shoot)
+}
+
+// isMultiZonal returns true if the shoot control plane tolerates zone failures, the controller replicas are then
+// spread over the zones
+func isMultiZonal(cluster *extensionscontroller.Cluster) bool {
+	return cluster != nil && cluster.Shoot != nil && v1beta1helper.IsMultiZonalShootControlPlane(cluster.Shoot)
+}
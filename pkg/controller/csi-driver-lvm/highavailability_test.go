@@ -0,0 +1,115 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestIsHighAvailability(t *testing.T) {
+	haCluster := func(failureToleranceType gardencorev1beta1.FailureToleranceType) *extensionscontroller.Cluster {
+		return &extensionscontroller.Cluster{
+			Shoot: &gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					ControlPlane: &gardencorev1beta1.ControlPlane{
+						HighAvailability: &gardencorev1beta1.HighAvailability{
+							FailureTolerance: gardencorev1beta1.FailureTolerance{Type: failureToleranceType},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tt := []struct {
+		desc             string
+		highAvailability *bool
+		cluster          *extensionscontroller.Cluster
+		want             bool
+		wantMultiZonal   bool
+	}{
+		{
+			desc:    "test shoot without failure tolerance",
+			cluster: &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			want:    false,
+		},
+		{
+			desc:    "test shoot with node failure tolerance",
+			cluster: haCluster(gardencorev1beta1.FailureToleranceTypeNode),
+			want:    true,
+		},
+		{
+			desc:           "test shoot with zone failure tolerance",
+			cluster:        haCluster(gardencorev1beta1.FailureToleranceTypeZone),
+			want:           true,
+			wantMultiZonal: true,
+		},
+		{
+			desc:             "test explicitly disabled",
+			highAvailability: ptr.To(false),
+			cluster:          haCluster(gardencorev1beta1.FailureToleranceTypeZone),
+			want:             false,
+			wantMultiZonal:   true,
+		},
+		{
+			desc:             "test explicitly enabled",
+			highAvailability: ptr.To(true),
+			want:             true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, isHighAvailability(&v1alpha1.CsiDriverLvmConfig{HighAvailability: tc.highAvailability}, tc.cluster))
+			assert.Equal(t, tc.wantMultiZonal, isMultiZonal(tc.cluster))
+		})
+	}
+}
+
+func TestHighAvailabilityObjects(t *testing.T) {
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	csidriverlvmConfig.ConfigureDefaults(ptr.To("/etc/lvm"), ptr.To("/dev/nvme[0-9]n[0-9]"))
+
+	a := &actuator{chartRenderer: newChartRenderer()}
+	objects, err := a.renderShootChart(csidriverlvmConfig, pluginGroups(csidriverlvmConfig, config.ControllerConfiguration{}, nil), goldenImages, true, true, nil)
+	require.NoError(t, err)
+
+	var (
+		statefulSet         *appsv1.StatefulSet
+		podDisruptionBudget *policyv1.PodDisruptionBudget
+		kinds               []string
+	)
+	for _, object := range objects[v1alpha1.ShootCsiDriverLvmControllerResourceName] {
+		assert.Equal(t, "kube-system", object.GetNamespace())
+		kinds = append(kinds, object.GetObjectKind().GroupVersionKind().Kind)
+
+		switch obj := object.(type) {
+		case *appsv1.StatefulSet:
+			statefulSet = obj
+		case *policyv1.PodDisruptionBudget:
+			podDisruptionBudget = obj
+		}
+	}
+	assert.Contains(t, kinds, "Role")
+	assert.Contains(t, kinds, "RoleBinding")
+
+	require.NotNil(t, statefulSet)
+	assert.Equal(t, ptr.To(highAvailabilityReplicas), statefulSet.Spec.Replicas)
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		assert.Subset(t, container.Args, []string{"--leader-election", "--leader-election-namespace=kube-system"})
+	}
+	require.Len(t, statefulSet.Spec.Template.Spec.TopologySpreadConstraints, 1)
+	assert.Equal(t, corev1.LabelTopologyZone, statefulSet.Spec.Template.Spec.TopologySpreadConstraints[0].TopologyKey)
+
+	require.NotNil(t, podDisruptionBudget)
+	assert.Equal(t, statefulSet.Spec.Selector, podDisruptionBudget.Spec.Selector)
+}
@@ -0,0 +1,251 @@
+package csidriverlvm
+
+import (
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/pkg/utils/timewindow"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/imagevector"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	imageCsiDriverLvm            string = "csi-driver-lvm"
+	imageCsiDriverLvmProvisioner string = "csi-driver-lvm-provisioner"
+	imageCsiAttacher             string = "csi-attacher"
+	imageCsiProvisioner          string = "csi-provisioner"
+	imageCsiResizer              string = "csi-resizer"
+	imageCsiNodeDriverRegistrar  string = "csi-node-driver-registrar"
+	imageLivenessprobe           string = "livenessprobe"
+
+	// canaryAnnotation can be set on the Cluster of a shoot to select it for the images of the image vector
+	canaryAnnotation string = "csi-driver-lvm.metal.extensions.gardener.cloud/canary"
+
+	// provisionerImageArg is the argument of the plugin container passing the image of the provisioner pods
+	provisionerImageArg string = "--provisionerimage="
+
+	// maintenanceGracePeriod is the time after the last maintenance of the shoot in which held back images are rolled
+	// out, it covers the reconciliation which gardener triggers after the maintenance
+	maintenanceGracePeriod = time.Hour
+)
+
+// imageNames are the names of the images deployed into the shoot in the image vector
+var imageNames = []string{
+	imageCsiDriverLvm,
+	imageCsiDriverLvmProvisioner,
+	imageCsiAttacher,
+	imageCsiProvisioner,
+	imageCsiResizer,
+	imageCsiNodeDriverRegistrar,
+	imageLivenessprobe,
+}
+
+// findImages returns the images of the image vector deployed into the shoot by their name
+func findImages() (map[string]string, error) {
+	images := map[string]string{}
+	for _, name := range imageNames {
+		image, err := imagevector.ImageVector().FindImage(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find %s image: %w", name, err)
+		}
+		images[name] = image.String()
+	}
+
+	return images, nil
+}
+
+// ProvisionerImageRepositories returns the repositories of the images of the provisioner pods, the pods are started
+// with the image of the image vector or the stable image of the rollout policy
+func ProvisionerImageRepositories(controllerConfig config.ControllerConfiguration) ([]string, error) {
+	image, err := imagevector.ImageVector().FindImage(imageCsiDriverLvmProvisioner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s image: %w", imageCsiDriverLvmProvisioner, err)
+	}
+
+	repositories := []string{imageRepository(image.String())}
+	if policy := controllerConfig.RolloutPolicy; policy != nil {
+		if stable, ok := policy.StableImages[imageCsiDriverLvmProvisioner]; ok && !slices.Contains(repositories, imageRepository(stable)) {
+			repositories = append(repositories, imageRepository(stable))
+		}
+	}
+
+	return repositories, nil
+}
+
+// effectiveImages returns the images to deploy into the shoot. Images which differ from the previously deployed ones
+// are held back until the maintenance window of the shoot opens or the shoot was maintained recently, unless the
+// update is forced. The second return value is true if images are held back.
+func effectiveImages(desired, previous map[string]string, cluster *extensionscontroller.Cluster, now time.Time, force bool) (map[string]string, bool) {
+	if force || len(previous) == 0 || maps.Equal(desired, previous) || isMaintenanceAllowed(cluster, now) {
+		return desired, false
+	}
+
+	images := overlayImages(desired, previous)
+	return images, !maps.Equal(images, desired)
+}
+
+// rolloutImages returns the image set of the shoot and the images it should receive according to the rollout policy.
+// Shoots selected by the policy receive the images of the image vector, the other shoots receive the stable images of
+// the policy. Images without a stable image keep their previously deployed image, so that changing the stable images
+// promotes them in all shoots outside of the selection.
+func rolloutImages(policy *config.RolloutPolicy, desired, previous map[string]string, cluster *extensionscontroller.Cluster) (string, map[string]string) {
+	if policy == nil {
+		return v1alpha1.ImageSetStable, desired
+	}
+	if isCanary(policy, cluster) {
+		return v1alpha1.ImageSetCanary, desired
+	}
+
+	return v1alpha1.ImageSetStable, overlayImages(overlayImages(desired, previous), policy.StableImages)
+}
+
+// isCanary returns true if the shoot is selected by the rollout policy
+func isCanary(policy *config.RolloutPolicy, cluster *extensionscontroller.Cluster) bool {
+	if cluster == nil {
+		return false
+	}
+
+	if cluster.ObjectMeta.Annotations[canaryAnnotation] == "true" {
+		return true
+	}
+
+	if cluster.Shoot != nil && cluster.Shoot.Spec.Purpose != nil && slices.Contains(policy.Purposes, string(*cluster.Shoot.Spec.Purpose)) {
+		return true
+	}
+
+	if policy.Percentage != nil {
+		hash := fnv.New32a()
+		_, _ = hash.Write([]byte(cluster.ObjectMeta.Name))
+		return int32(hash.Sum32()%100) < *policy.Percentage
+	}
+
+	return false
+}
+
+// overlayImages returns the desired images with the images of the overlay, images unknown to the image vector are dropped
+func overlayImages(desired, overlay map[string]string) map[string]string {
+	images := maps.Clone(desired)
+	for name, image := range overlay {
+		if _, ok := images[name]; ok {
+			images[name] = image
+		}
+	}
+	return images
+}
+
+// isMaintenanceAllowed returns true if the maintenance window of the shoot is open or the shoot was maintained within
+// the grace period, shoots without a maintenance window are always maintained.
+// The maintain operation annotation cannot be used, gardener replaces it with the reconcile operation before the shoot
+// is copied into the Cluster, but it records the maintenance in the status of the shoot.
+func isMaintenanceAllowed(cluster *extensionscontroller.Cluster, now time.Time) bool {
+	if cluster == nil || cluster.Shoot == nil {
+		return true
+	}
+	shoot := cluster.Shoot
+
+	if last := shoot.Status.LastMaintenance; last != nil && !last.TriggeredTime.IsZero() &&
+		!now.Before(last.TriggeredTime.Time) && now.Sub(last.TriggeredTime.Time) < maintenanceGracePeriod {
+		return true
+	}
+
+	if shoot.Spec.Maintenance == nil || shoot.Spec.Maintenance.TimeWindow == nil {
+		return true
+	}
+
+	window, err := timewindow.ParseMaintenanceTimeWindow(shoot.Spec.Maintenance.TimeWindow.Begin, shoot.Spec.Maintenance.TimeWindow.End)
+	if err != nil {
+		return true
+	}
+
+	return window.Contains(now)
+}
+
+// untilMaintenanceWindow returns the time until the next maintenance window of the shoot begins, it is zero if the
+// shoot has no maintenance window
+func untilMaintenanceWindow(cluster *extensionscontroller.Cluster, now time.Time) time.Duration {
+	if cluster == nil || cluster.Shoot == nil || cluster.Shoot.Spec.Maintenance == nil || cluster.Shoot.Spec.Maintenance.TimeWindow == nil {
+		return 0
+	}
+	timeWindow := cluster.Shoot.Spec.Maintenance.TimeWindow
+
+	window, err := timewindow.ParseMaintenanceTimeWindow(timeWindow.Begin, timeWindow.End)
+	if err != nil {
+		return 0
+	}
+
+	begin := window.AdjustedBegin(now)
+	if !begin.After(now) {
+		begin = begin.AddDate(0, 0, 1)
+	}
+	return begin.Sub(now)
+}
+
+// deployedImages returns the images of the containers of the given objects by their name in the desired images, the
+// images are matched by their repository. Images with an unknown repository are ignored.
+func deployedImages(objects []client.Object, desired map[string]string) map[string]string {
+	names := map[string]string{}
+	for name, image := range desired {
+		names[imageRepository(image)] = name
+	}
+
+	images := map[string]string{}
+	add := func(image string) {
+		if name, ok := names[imageRepository(image)]; ok {
+			images[name] = image
+		}
+	}
+
+	for _, obj := range objects {
+		var podSpec corev1.PodSpec
+		switch o := obj.(type) {
+		case *appsv1.DaemonSet:
+			podSpec = o.Spec.Template.Spec
+		case *appsv1.StatefulSet:
+			podSpec = o.Spec.Template.Spec
+		default:
+			continue
+		}
+
+		for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+			add(container.Image)
+			// the provisioner pods are started by the plugin with the image passed as argument
+			for _, arg := range container.Args {
+				if image, ok := strings.CutPrefix(arg, provisionerImageArg); ok {
+					add(image)
+				}
+			}
+		}
+	}
+
+	return images
+}
+
+// imageRepository returns the image reference without its tag and digest
+func imageRepository(image string) string {
+	tag := imageTag(image)
+	image, _, _ = strings.Cut(image, "@")
+	if tag == "" {
+		return image
+	}
+	return strings.TrimSuffix(image, ":"+tag)
+}
+
+// imageTag returns the tag of the image reference, it is empty if the image is referenced without a tag
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	index := strings.LastIndex(image, ":")
+	if index < 0 || strings.Contains(image[index:], "/") {
+		return ""
+	}
+	return image[index+1:]
+}
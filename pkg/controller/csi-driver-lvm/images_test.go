@@ -0,0 +1,357 @@
+package csidriverlvm
+
+import (
+	"testing"
+	"time"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEffectiveImages(t *testing.T) {
+	outsideWindow := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	insideWindow := time.Date(2024, 1, 1, 22, 30, 0, 0, time.UTC)
+
+	// the Cluster as gardenlet writes it, the operation annotation of the shoot was already replaced by gardener
+	cluster := func(lastMaintenance *gardencorev1beta1.LastMaintenance) *extensionscontroller.Cluster {
+		return &extensionscontroller.Cluster{
+			Shoot: &gardencorev1beta1.Shoot{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{v1beta1constants.GardenerOperation: v1beta1constants.GardenerOperationReconcile}},
+				Spec: gardencorev1beta1.ShootSpec{
+					Maintenance: &gardencorev1beta1.Maintenance{
+						TimeWindow: &gardencorev1beta1.MaintenanceTimeWindow{Begin: "220000+0000", End: "230000+0000"},
+					},
+				},
+				Status: gardencorev1beta1.ShootStatus{LastMaintenance: lastMaintenance},
+			},
+		}
+	}
+	maintained := func(triggered time.Time) *gardencorev1beta1.LastMaintenance {
+		return &gardencorev1beta1.LastMaintenance{
+			Description:   "Maintenance succeeded",
+			TriggeredTime: metav1.NewTime(triggered),
+			State:         gardencorev1beta1.LastOperationStateSucceeded,
+		}
+	}
+
+	desired := map[string]string{
+		imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.7.0",
+		imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+	}
+	previous := map[string]string{
+		imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+		imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+		"removed":           "example.com/removed:v1",
+	}
+
+	tt := []struct {
+		desc     string
+		previous map[string]string
+		cluster  *extensionscontroller.Cluster
+		now      time.Time
+		force    bool
+		want     map[string]string
+		wantHeld bool
+	}{
+		{
+			desc:    "test initial deployment",
+			cluster: cluster(nil),
+			now:     outsideWindow,
+			want:    desired,
+		},
+		{
+			desc:     "test held outside of the maintenance window",
+			previous: previous,
+			cluster:  cluster(nil),
+			now:      outsideWindow,
+			want: map[string]string{
+				imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+				imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+			},
+			wantHeld: true,
+		},
+		{
+			desc:     "test updated in the maintenance window",
+			previous: previous,
+			cluster:  cluster(nil),
+			now:      insideWindow,
+			want:     desired,
+		},
+		{
+			desc:     "test updated after the maintain operation",
+			previous: previous,
+			cluster:  cluster(maintained(outsideWindow.Add(-5 * time.Minute))),
+			now:      outsideWindow,
+			want:     desired,
+		},
+		{
+			desc:     "test held after an earlier maintenance",
+			previous: previous,
+			cluster:  cluster(maintained(outsideWindow.Add(-24 * time.Hour))),
+			now:      outsideWindow,
+			want: map[string]string{
+				imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+				imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+			},
+			wantHeld: true,
+		},
+		{
+			desc:     "test forced outside of the maintenance window",
+			previous: previous,
+			cluster:  cluster(nil),
+			now:      outsideWindow,
+			force:    true,
+			want:     desired,
+		},
+		{
+			desc:     "test updated without maintenance window",
+			previous: previous,
+			cluster:  &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			now:      outsideWindow,
+			want:     desired,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, held := effectiveImages(desired, tc.previous, tc.cluster, tc.now, tc.force)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantHeld, held)
+		})
+	}
+}
+
+func TestRolloutImagesPromotion(t *testing.T) {
+	desired := map[string]string{
+		imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.7.0",
+		imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.7.0",
+		imageCsiProvisioner:          "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+	}
+	oldStable := map[string]string{
+		imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.5.0",
+		imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.5.0",
+	}
+	newStable := map[string]string{
+		imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+		imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0",
+	}
+	cluster := &extensionscontroller.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "shoot--test--test"},
+		Shoot:      &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{Purpose: ptr.To(gardencorev1beta1.ShootPurposeProduction)}},
+	}
+	policy := &config.RolloutPolicy{Purposes: []string{"evaluation"}, StableImages: oldStable}
+
+	// the shoot was deployed with the old image set
+	imageSet, previous := rolloutImages(policy, desired, nil, cluster)
+	assert.Equal(t, v1alpha1.ImageSetStable, imageSet)
+	assert.Equal(t, overlayImages(desired, oldStable), previous)
+
+	// the operator promotes the new image set after it was tested in the canary shoots
+	policy.StableImages = newStable
+	imageSet, images := rolloutImages(policy, desired, previous, cluster)
+	assert.Equal(t, v1alpha1.ImageSetStable, imageSet)
+	assert.Equal(t, map[string]string{
+		imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+		imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0",
+		imageCsiProvisioner:          "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+	}, images)
+
+	// removing the policy promotes the images of the image vector
+	imageSet, images = rolloutImages(nil, desired, images, cluster)
+	assert.Equal(t, v1alpha1.ImageSetStable, imageSet)
+	assert.Equal(t, desired, images)
+}
+
+func TestUntilMaintenanceWindow(t *testing.T) {
+	cluster := func(begin, end string) *extensionscontroller.Cluster {
+		return &extensionscontroller.Cluster{
+			Shoot: &gardencorev1beta1.Shoot{
+				Spec: gardencorev1beta1.ShootSpec{
+					Maintenance: &gardencorev1beta1.Maintenance{
+						TimeWindow: &gardencorev1beta1.MaintenanceTimeWindow{Begin: begin, End: end},
+					},
+				},
+			},
+		}
+	}
+
+	tt := []struct {
+		desc    string
+		cluster *extensionscontroller.Cluster
+		now     time.Time
+		want    time.Duration
+	}{
+		{
+			desc:    "test before the window",
+			cluster: cluster("220000+0000", "230000+0000"),
+			now:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want:    10 * time.Hour,
+		},
+		{
+			desc:    "test after the window",
+			cluster: cluster("220000+0000", "230000+0000"),
+			now:     time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC),
+			want:    22*time.Hour + 30*time.Minute,
+		},
+		{
+			desc:    "test window spanning midnight",
+			cluster: cluster("230000+0000", "010000+0000"),
+			now:     time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC),
+			want:    21 * time.Hour,
+		},
+		{
+			desc:    "test without maintenance window",
+			cluster: &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			now:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			want:    0,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, untilMaintenanceWindow(tc.cluster, tc.now))
+		})
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tt := []struct {
+		image string
+		want  string
+	}{
+		{image: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0", want: "v0.6.0"},
+		{image: "localhost:5000/csi-driver-lvm:v0.6.0", want: "v0.6.0"},
+		{image: "localhost:5000/csi-driver-lvm", want: ""},
+		{image: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0@sha256:abc", want: "v0.6.0"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.want, imageTag(tc.image))
+		})
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tt := []struct {
+		image string
+		want  string
+	}{
+		{image: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0", want: "ghcr.io/metal-stack/csi-driver-lvm"},
+		{image: "localhost:5000/csi-driver-lvm:v0.6.0", want: "localhost:5000/csi-driver-lvm"},
+		{image: "localhost:5000/csi-driver-lvm", want: "localhost:5000/csi-driver-lvm"},
+		{image: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0@sha256:abc", want: "ghcr.io/metal-stack/csi-driver-lvm"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.image, func(t *testing.T) {
+			assert.Equal(t, tc.want, imageRepository(tc.image))
+		})
+	}
+}
+
+func TestRolloutImages(t *testing.T) {
+	desired := map[string]string{
+		imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.7.0",
+		imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+	}
+	previous := map[string]string{
+		imageCsiDriverLvm: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+	}
+	stable := map[string]string{
+		imageCsiDriverLvm: "ghcr.io/metal-stack/csi-driver-lvm:v0.5.0",
+	}
+	cluster := func(name string, annotations map[string]string, purpose gardencorev1beta1.ShootPurpose) *extensionscontroller.Cluster {
+		return &extensionscontroller.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations},
+			Shoot:      &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{Purpose: &purpose}},
+		}
+	}
+
+	tt := []struct {
+		desc         string
+		policy       *config.RolloutPolicy
+		previous     map[string]string
+		cluster      *extensionscontroller.Cluster
+		wantImageSet string
+		want         map[string]string
+	}{
+		{
+			desc:         "test without rollout policy",
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetStable,
+			want:         desired,
+		},
+		{
+			desc:         "test selected by purpose",
+			policy:       &config.RolloutPolicy{Purposes: []string{"evaluation"}},
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeEvaluation),
+			wantImageSet: v1alpha1.ImageSetCanary,
+			want:         desired,
+		},
+		{
+			desc:         "test selected by cluster annotation",
+			policy:       &config.RolloutPolicy{Purposes: []string{"evaluation"}},
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", map[string]string{canaryAnnotation: "true"}, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetCanary,
+			want:         desired,
+		},
+		{
+			desc:         "test selected by percentage",
+			policy:       &config.RolloutPolicy{Percentage: ptr.To(int32(100))},
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetCanary,
+			want:         desired,
+		},
+		{
+			desc:         "test not selected keeps previous images",
+			policy:       &config.RolloutPolicy{Percentage: ptr.To(int32(0)), Purposes: []string{"evaluation"}},
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetStable,
+			want: map[string]string{
+				imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0",
+				imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+			},
+		},
+		{
+			desc:         "test not selected receives stable images",
+			policy:       &config.RolloutPolicy{Purposes: []string{"evaluation"}, StableImages: stable},
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetStable,
+			want: map[string]string{
+				imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.5.0",
+				imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+			},
+		},
+		{
+			desc:         "test not selected with previous images follows stable images",
+			policy:       &config.RolloutPolicy{Purposes: []string{"evaluation"}, StableImages: stable},
+			previous:     previous,
+			cluster:      cluster("shoot--test--test", nil, gardencorev1beta1.ShootPurposeProduction),
+			wantImageSet: v1alpha1.ImageSetStable,
+			want: map[string]string{
+				imageCsiDriverLvm:   "ghcr.io/metal-stack/csi-driver-lvm:v0.5.0",
+				imageCsiProvisioner: "registry.k8s.io/sig-storage/csi-provisioner:v5.0.0",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			imageSet, got := rolloutImages(tc.policy, desired, tc.previous, tc.cluster)
+			assert.Equal(t, tc.wantImageSet, imageSet)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package csidriverlvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+const (
+	// installFieldOwner is the field manager of the objects applied by the standalone installation
+	installFieldOwner string = "gardener-extension-csi-driver-lvm"
+
+	// installManagedByLabel marks the objects of the standalone installation, they are pruned by it
+	installManagedByLabel string = "app.kubernetes.io/managed-by"
+	// installComponentLabel contains the component of an object of the standalone installation
+	installComponentLabel string = "csi-driver-lvm.metal.extensions.gardener.cloud/component"
+)
+
+// installedKinds are the kinds of the objects which can be part of the standalone installation
+var installedKinds = []schema.GroupVersionKind{
+	{Group: "", Version: "v1", Kind: "ServiceAccount"},
+	{Group: "", Version: "v1", Kind: "ConfigMap"},
+	{Group: "", Version: "v1", Kind: "Secret"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	{Group: "storage.k8s.io", Version: "v1", Kind: "CSIDriver"},
+	{Group: "storage.k8s.io", Version: "v1", Kind: "StorageClass"},
+}
+
+// InstallOptions contains the inputs to install the driver into a cluster outside of Gardener
+type InstallOptions struct {
+	// Config is the configuration of the driver
+	Config *v1alpha1.CsiDriverLvmConfig
+	// ControllerConfig is the configuration of the operator providing the defaults
+	ControllerConfig config.ControllerConfiguration
+}
+
+// Install applies the objects deployed into shoots directly to a cluster outside of Gardener with server-side apply.
+// The configuration is validated the same way as by the actuator and the installation is refused while the old
+// csi-lvm exists in the cluster. Objects of a previous installation which are no longer rendered are pruned, storage
+// classes whose immutable fields changed are recreated.
+// Storage classes with encryption are not supported as their keys are referenced by the resources of a shoot.
+func Install(ctx context.Context, log logr.Logger, c client.Client, opts InstallOptions) error {
+	if err := validateInstall(opts.Config); err != nil {
+		return err
+	}
+
+	isOldCsiLvmExisting, err := isOldCsiLvmExisting(ctx, c)
+	if err != nil {
+		return fmt.Errorf("failed to check if old csi-lvm is existing: %w", err)
+	}
+	if isOldCsiLvmExisting {
+		return errors.New("old csi-lvm is existing in the cluster, it must be removed before installing csi-driver-lvm")
+	}
+
+	components, err := renderComponents(ctx, log, RenderOptions{Config: opts.Config, ControllerConfig: opts.ControllerConfig}, c)
+	if err != nil {
+		return err
+	}
+
+	for _, component := range components {
+		applied := sets.New[string]()
+		for _, object := range component.objects {
+			obj, err := applyObject(object, component.managedResourceName)
+			if err != nil {
+				return err
+			}
+
+			// some of the cluster scoped objects are rendered with a namespace which is ignored by the api server
+			namespaced, err := c.IsObjectNamespaced(obj)
+			if err != nil {
+				return fmt.Errorf("failed to determine the scope of %s %q: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			if !namespaced {
+				obj.SetNamespace("")
+			}
+
+			if err := recreateChangedStorageClass(ctx, log, c, obj); err != nil {
+				return err
+			}
+
+			if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(installFieldOwner), client.ForceOwnership); err != nil {
+				return fmt.Errorf("failed to apply %s %q: %w", obj.GetKind(), client.ObjectKeyFromObject(obj), err)
+			}
+			log.Info("applied object", "kind", obj.GetKind(), "name", client.ObjectKeyFromObject(obj))
+
+			applied.Insert(installedObjectKey(obj))
+		}
+
+		if err := prune(ctx, log, c, component.managedResourceName, applied); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateInstall returns an error for settings which are not supported by the standalone installation
+func validateInstall(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) error {
+	if csidriverlvmConfig == nil {
+		return nil
+	}
+
+	var encrypted []string
+	for _, sc := range mergedStorageClasses(csidriverlvmConfig) {
+		if sc.Encryption != nil {
+			encrypted = append(encrypted, sc.Name)
+		}
+	}
+	if len(encrypted) > 0 {
+		return fmt.Errorf("encryption of storage classes %s is not supported by the standalone installation, the passphrases can only be referenced by the resources of a shoot", strings.Join(encrypted, ", "))
+	}
+
+	return nil
+}
+
+// recreateChangedStorageClass deletes the installed storage class if one of its immutable fields differs from the
+// object which is applied, the api server rejects the update otherwise. Volumes provisioned before keep their settings.
+func recreateChangedStorageClass(ctx context.Context, log logr.Logger, c client.Client, obj *unstructured.Unstructured) error {
+	if obj.GroupVersionKind() != storagev1.SchemeGroupVersion.WithKind("StorageClass") {
+		return nil
+	}
+
+	desired := &storagev1.StorageClass{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, desired); err != nil {
+		return fmt.Errorf("failed to convert storage class %q: %w", obj.GetName(), err)
+	}
+
+	existing := &storagev1.StorageClass{}
+	if err := c.Get(ctx, client.ObjectKey{Name: desired.Name}, existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if isStorageClassUpdatable(existing, desired) {
+		return nil
+	}
+
+	if err := client.IgnoreNotFound(c.Delete(ctx, existing)); err != nil {
+		return fmt.Errorf("failed to delete storage class %q: %w", existing.Name, err)
+	}
+	log.Info("deleted storage class with changed immutable fields", "name", existing.Name)
+
+	return nil
+}
+
+// isStorageClassUpdatable returns true if the immutable fields of the storage class are unchanged, unset fields of
+// the desired storage class are compared with the defaults of the api server
+func isStorageClassUpdatable(existing, desired *storagev1.StorageClass) bool {
+	reclaimPolicy := ptr.Deref(desired.ReclaimPolicy, corev1.PersistentVolumeReclaimDelete)
+	volumeBindingMode := ptr.Deref(desired.VolumeBindingMode, storagev1.VolumeBindingImmediate)
+
+	return existing.Provisioner == desired.Provisioner &&
+		maps.Equal(existing.Parameters, desired.Parameters) &&
+		ptr.Deref(existing.ReclaimPolicy, corev1.PersistentVolumeReclaimDelete) == reclaimPolicy &&
+		ptr.Deref(existing.VolumeBindingMode, storagev1.VolumeBindingImmediate) == volumeBindingMode
+}
+
+// Uninstall removes all objects of the standalone installation from the cluster, the components are removed in the
+// reverse order of their installation
+func Uninstall(ctx context.Context, log logr.Logger, c client.Client) error {
+	names := slices.Clone(shootComponentNames)
+	slices.Reverse(names)
+
+	for _, name := range names {
+		if err := prune(ctx, log, c, name, sets.New[string]()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prune deletes the objects of the component which are not contained in the keep set
+func prune(ctx context.Context, log logr.Logger, c client.Client, component string, keep sets.Set[string]) error {
+	for _, gvk := range installedKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		if err := c.List(ctx, list, client.MatchingLabels{installManagedByLabel: installFieldOwner, installComponentLabel: component}); err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvk.Kind, err)
+		}
+
+		for _, obj := range list.Items {
+			obj.SetGroupVersionKind(gvk)
+			if keep.Has(installedObjectKey(&obj)) {
+				continue
+			}
+
+			if err := client.IgnoreNotFound(c.Delete(ctx, &obj, client.PropagationPolicy(metav1.DeletePropagationBackground))); err != nil {
+				return fmt.Errorf("failed to delete %s %q: %w", gvk.Kind, client.ObjectKeyFromObject(&obj), err)
+			}
+			log.Info("deleted object", "kind", gvk.Kind, "name", client.ObjectKeyFromObject(&obj))
+		}
+	}
+
+	return nil
+}
+
+// applyObject converts the object into the unstructured object applied to the cluster, the status is dropped as it
+// is owned by the controllers of the cluster
+func applyObject(object client.Object, component string) (*unstructured.Unstructured, error) {
+	gvk, err := apiutil.GVKForObject(object, kubernetes.ShootScheme)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s %q: %w", gvk.Kind, client.ObjectKeyFromObject(object), err)
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(gvk)
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[installManagedByLabel] = installFieldOwner
+	labels[installComponentLabel] = component
+	obj.SetLabels(labels)
+
+	return obj, nil
+}
+
+func installedObjectKey(obj *unstructured.Unstructured) string {
+	return obj.GetKind() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,174 @@
+package csidriverlvm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+// newInstallTestClient returns a fake client which handles server-side apply patches by creating or updating the
+// object as the fake client does not support them
+func newInstallTestClient(objects ...client.Object) client.Client {
+	groupVersions := []schema.GroupVersion{corev1.SchemeGroupVersion}
+	for _, gvk := range installedKinds {
+		groupVersions = append(groupVersions, gvk.GroupVersion())
+	}
+
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	for _, gvk := range installedKinds {
+		switch gvk.Kind {
+		case "ClusterRole", "ClusterRoleBinding", "CSIDriver", "StorageClass":
+			mapper.Add(gvk, meta.RESTScopeRoot)
+		default:
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+		}
+	}
+
+	return fake.NewClientBuilder().WithScheme(kubernetes.ShootScheme).WithRESTMapper(mapper).WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if patch.Type() != "application/apply-patch+yaml" {
+				return c.Patch(ctx, obj, patch, opts...)
+			}
+
+			current := obj.DeepCopyObject().(client.Object)
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+				if apierrors.IsNotFound(err) {
+					return c.Create(ctx, obj)
+				}
+				return err
+			}
+			obj.SetResourceVersion(current.GetResourceVersion())
+			return c.Update(ctx, obj)
+		},
+	}).Build()
+}
+
+func TestInstall(t *testing.T) {
+	ctx := context.Background()
+	controllerConfig := config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")}
+	custom := v1alpha1.StorageClass{Name: "custom", Type: ptr.To(v1alpha1.VolumeTypeLinear)}
+
+	c := newInstallTestClient()
+
+	err := Install(ctx, logr.Discard(), c, InstallOptions{
+		Config:           &v1alpha1.CsiDriverLvmConfig{StorageClasses: []v1alpha1.StorageClass{custom}},
+		ControllerConfig: controllerConfig,
+	})
+	require.NoError(t, err)
+
+	storageClass := &storagev1.StorageClass{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "custom"}, storageClass))
+	assert.Equal(t, installFieldOwner, storageClass.Labels[installManagedByLabel])
+	assert.Equal(t, v1alpha1.ShootCsiDriverLvmStorageClassesResourceName, storageClass.Labels[installComponentLabel])
+
+	// the cluster role is cluster scoped although it is rendered with a namespace
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Name: controllerName}, &rbacv1.ClusterRole{}))
+
+	// objects which are no longer rendered are pruned, unrelated objects are kept
+	unrelated := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "unrelated"}, Provisioner: "example.com/unrelated"}
+	require.NoError(t, c.Create(ctx, unrelated))
+
+	err = Install(ctx, logr.Discard(), c, InstallOptions{ControllerConfig: controllerConfig})
+	require.NoError(t, err)
+
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Name: "custom"}, &storagev1.StorageClass{})))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Name: "csi-lvm"}, &storagev1.StorageClass{}))
+	assert.NoError(t, c.Get(ctx, client.ObjectKey{Name: controllerName}, &rbacv1.ClusterRole{}))
+
+	require.NoError(t, Uninstall(ctx, logr.Discard(), c))
+
+	storageClasses := &storagev1.StorageClassList{}
+	require.NoError(t, c.List(ctx, storageClasses))
+	require.Len(t, storageClasses.Items, 1)
+	assert.Equal(t, "unrelated", storageClasses.Items[0].Name)
+
+	serviceAccounts := &corev1.ServiceAccountList{}
+	require.NoError(t, c.List(ctx, serviceAccounts))
+	assert.Empty(t, serviceAccounts.Items)
+}
+
+func TestInstallWithOldCsiLvm(t *testing.T) {
+	c := newInstallTestClient(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: oldNamespace}})
+
+	err := Install(context.Background(), logr.Discard(), c, InstallOptions{
+		ControllerConfig: config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "old csi-lvm is existing")
+
+	storageClasses := &storagev1.StorageClassList{}
+	require.NoError(t, c.List(context.Background(), storageClasses))
+	assert.Empty(t, storageClasses.Items)
+}
+
+func TestInstallWithEncryption(t *testing.T) {
+	c := newInstallTestClient()
+
+	err := Install(context.Background(), logr.Discard(), c, InstallOptions{
+		Config: &v1alpha1.CsiDriverLvmConfig{StorageClasses: []v1alpha1.StorageClass{
+			{Name: "encrypted", Type: ptr.To(v1alpha1.VolumeTypeLinear), Encryption: &v1alpha1.Encryption{SecretResourceName: "luks"}},
+		}},
+		ControllerConfig: config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]"), AllowEncryption: ptr.To(true)},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `encryption of storage classes encrypted is not supported by the standalone installation`)
+
+	// nothing is applied to the cluster
+	storageClasses := &storagev1.StorageClassList{}
+	require.NoError(t, c.List(context.Background(), storageClasses))
+	assert.Empty(t, storageClasses.Items)
+}
+
+func TestInstallRecreatesChangedStorageClass(t *testing.T) {
+	ctx := context.Background()
+	controllerConfig := config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")}
+
+	deleted := []string{}
+	c := interceptor.NewClient(newInstallTestClient().(client.WithWatch), interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if _, ok := obj.(*storagev1.StorageClass); ok {
+				deleted = append(deleted, obj.GetName())
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	install := func(volumeType string) *storagev1.StorageClass {
+		require.NoError(t, Install(ctx, logr.Discard(), c, InstallOptions{
+			Config:           &v1alpha1.CsiDriverLvmConfig{StorageClasses: []v1alpha1.StorageClass{{Name: "custom", Type: ptr.To(volumeType)}}},
+			ControllerConfig: controllerConfig,
+		}))
+
+		storageClass := &storagev1.StorageClass{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Name: "custom"}, storageClass))
+		return storageClass
+	}
+
+	install(v1alpha1.VolumeTypeLinear)
+	install(v1alpha1.VolumeTypeLinear)
+	assert.Empty(t, deleted)
+
+	// the parameters of a storage class are immutable, it is deleted and applied again
+	changed := install(v1alpha1.VolumeTypeMirror)
+	assert.Equal(t, []string{"custom"}, deleted)
+	assert.Equal(t, v1alpha1.VolumeTypeMirror, changed.Parameters["type"])
+}
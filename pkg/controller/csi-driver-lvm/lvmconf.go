@@ -0,0 +1,73 @@
+package csidriverlvm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+const (
+	// LvmConfigMapName is the name of the config map in the shoot containing the lvmlocal.conf
+	LvmConfigMapName string = "csi-driver-lvm-lvm-config"
+	// LvmConfigKey is the lvmlocal.conf, lvm reads it in addition to the lvm.conf of the image and its settings take
+	// precedence
+	LvmConfigKey string = "lvmlocal.conf"
+)
+
+// lvmConf returns the rendered lvmlocal.conf of the configuration, it is empty if no setting is configured
+func lvmConf(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) string {
+	if csidriverlvmConfig.LvmConfig == nil {
+		return ""
+	}
+	return renderLvmConf(csidriverlvmConfig.LvmConfig)
+}
+
+// renderLvmConf renders the settings of the lvmlocal.conf, settings which are not configured keep the lvm.conf of the image
+func renderLvmConf(lvmConfig *v1alpha1.LvmConfig) string {
+	var (
+		devices    []string
+		activation []string
+	)
+
+	if len(lvmConfig.DeviceFilter) > 0 {
+		filters := make([]string, 0, len(lvmConfig.DeviceFilter))
+		for _, filter := range lvmConfig.DeviceFilter {
+			filters = append(filters, fmt.Sprintf("%q", filter))
+		}
+		devices = append(devices, fmt.Sprintf("filter = [ %s ]", strings.Join(filters, ", ")))
+	}
+	if lvmConfig.IssueDiscards != nil {
+		issueDiscards := 0
+		if *lvmConfig.IssueDiscards {
+			issueDiscards = 1
+		}
+		devices = append(devices, fmt.Sprintf("issue_discards = %d", issueDiscards))
+	}
+	if lvmConfig.ThinPoolAutoextendThreshold != nil {
+		activation = append(activation, fmt.Sprintf("thin_pool_autoextend_threshold = %d", *lvmConfig.ThinPoolAutoextendThreshold))
+	}
+	if lvmConfig.ThinPoolAutoextendPercent != nil {
+		activation = append(activation, fmt.Sprintf("thin_pool_autoextend_percent = %d", *lvmConfig.ThinPoolAutoextendPercent))
+	}
+
+	var b strings.Builder
+	for _, section := range []struct {
+		name     string
+		settings []string
+	}{
+		{name: "devices", settings: devices},
+		{name: "activation", settings: activation},
+	} {
+		if len(section.settings) == 0 {
+			continue
+		}
+		b.WriteString(section.name + " {\n")
+		for _, setting := range section.settings {
+			b.WriteString("\t" + setting + "\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	return b.String()
+}
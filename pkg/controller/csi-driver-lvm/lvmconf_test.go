@@ -0,0 +1,46 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestRenderLvmConf(t *testing.T) {
+	tt := []struct {
+		desc      string
+		lvmConfig *v1alpha1.LvmConfig
+		expected  string
+	}{
+		{
+			desc:      "test empty config",
+			lvmConfig: &v1alpha1.LvmConfig{},
+			expected:  "",
+		},
+		{
+			desc: "test devices config",
+			lvmConfig: &v1alpha1.LvmConfig{
+				DeviceFilter:  []string{"a|^/dev/nvme[0-9]n1$|", "r|.*|"},
+				IssueDiscards: ptr.To(false),
+			},
+			expected: "devices {\n\tfilter = [ \"a|^/dev/nvme[0-9]n1$|\", \"r|.*|\" ]\n\tissue_discards = 0\n}\n",
+		},
+		{
+			desc: "test full config",
+			lvmConfig: &v1alpha1.LvmConfig{
+				IssueDiscards:               ptr.To(true),
+				ThinPoolAutoextendThreshold: ptr.To(int32(80)),
+				ThinPoolAutoextendPercent:   ptr.To(int32(20)),
+			},
+			expected: "devices {\n\tissue_discards = 1\n}\nactivation {\n\tthin_pool_autoextend_threshold = 80\n\tthin_pool_autoextend_percent = 20\n}\n",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, renderLvmConf(tc.lvmConfig))
+		})
+	}
+}
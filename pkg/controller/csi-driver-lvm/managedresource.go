@@ -0,0 +1,217 @@
+package csidriverlvm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	extensionsshootwebhook "github.com/gardener/gardener/extensions/pkg/webhook/shoot"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils"
+	"github.com/gardener/gardener/pkg/utils/kubernetes/health"
+	"github.com/gardener/gardener/pkg/utils/managedresources"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/version"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	managedResourceOrigin string = "csi-driver-lvm-extension"
+
+	// configHashAnnotation contains the hash of the inputs the objects of the managed resource were rendered from
+	configHashAnnotation string = "csi-driver-lvm.metal.extensions.gardener.cloud/config-hash"
+)
+
+// renderInput contains everything the objects deployed into the shoot are rendered from. Only the settings of the
+// controller configuration which are read while rendering are part of it, the others are already applied to the
+// config, the worker pools and the images or only affect the validation and the health checks.
+type renderInput struct {
+	Version          string                         `json:"version"`
+	Chart            string                         `json:"chart"`
+	Config           *v1alpha1.CsiDriverLvmConfig   `json:"config"`
+	AllowedPatches   []config.AllowedPatch          `json:"allowedPatches"`
+	DefaultPaths     *config.Paths                  `json:"defaultPaths"`
+	WorkerPools      []v1alpha1.WorkerPoolStatus    `json:"workerPools"`
+	Images           map[string]string              `json:"images"`
+	HighAvailability bool                           `json:"highAvailability"`
+	MultiZonal       bool                           `json:"multiZonal"`
+	EncryptionKeys   []v1alpha1.EncryptionKeyStatus `json:"encryptionKeys"`
+}
+
+// hash returns the hash of the render input, the version of the extension and the checksum of the shoot chart are part
+// of the hash as the rendering itself may change between versions
+func (r renderInput) hash() (string, error) {
+	r.Version = version.Version
+
+	chart, err := shootChartChecksum()
+	if err != nil {
+		return "", err
+	}
+	r.Chart = chart
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal render input: %w", err)
+	}
+
+	return utils.ComputeSHA256Hex(raw), nil
+}
+
+// shootComponent is a part of the deployment into the shoot which is managed by its own managed resource, a failing
+// component does not block the rollout of the other components
+type shootComponent struct {
+	managedResourceName string
+	objects             []client.Object
+}
+
+// shootComponentNames are the names of the managed resources of the components in the order they are deployed, they
+// are deleted in the reverse order. The storage classes are deployed last and deleted first so that no volumes are
+// provisioned without a controller and a plugin.
+var shootComponentNames = []string{
+	v1alpha1.ShootCsiDriverLvmPluginResourceName,
+	v1alpha1.ShootCsiDriverLvmControllerResourceName,
+	v1alpha1.ShootCsiDriverLvmStorageClassesResourceName,
+}
+
+// outdatedManagedResources returns the names of the managed resources of the components which were not rendered from
+// inputs with the given hash
+func (a *actuator) outdatedManagedResources(ctx context.Context, namespace, hash string) ([]string, error) {
+	outdated := []string{}
+	for _, name := range shootComponentNames {
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, managedResource)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get managed resource %q: %w", name, err)
+		}
+
+		if managedResource.Annotations[configHashAnnotation] != hash {
+			outdated = append(outdated, name)
+		}
+	}
+
+	return outdated, nil
+}
+
+// deployManagedResource serializes the objects into the secret of the managed resource and records the hash of their
+// inputs on the managed resource
+func (a *actuator) deployManagedResource(ctx context.Context, namespace string, component shootComponent, keepObjects bool, hash string) error {
+	data, err := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer).AddAllAndSerialize(component.objects...)
+	if err != nil {
+		return err
+	}
+
+	secretName, secret := managedresources.NewSecret(a.client, namespace, component.managedResourceName, data, true)
+	if err := secret.Reconcile(ctx); err != nil {
+		return fmt.Errorf("could not create or update secret of managed resource %q: %w", component.managedResourceName, err)
+	}
+
+	managedResource := managedresources.NewForShoot(a.client, namespace, component.managedResourceName, managedResourceOrigin, keepObjects).
+		WithSecretRef(secretName).
+		WithAnnotations(map[string]string{configHashAnnotation: hash})
+	if err := managedResource.Reconcile(ctx); err != nil {
+		return fmt.Errorf("could not create or update managed resource %q: %w", component.managedResourceName, err)
+	}
+
+	return nil
+}
+
+// releaseLegacyManagedResource prepares the single managed resource of previous versions of the extension for the
+// migration, its objects are kept in the shoot and adopted by the managed resources of the components. It returns
+// true if the legacy managed resource exists.
+func (a *actuator) releaseLegacyManagedResource(ctx context.Context, namespace string) (bool, error) {
+	managedResource := &resourcesv1alpha1.ManagedResource{}
+	err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: v1alpha1.ShootCsiDriverLvmResourceName}, managedResource)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get legacy managed resource: %w", err)
+	}
+
+	if err := managedresources.SetKeepObjects(ctx, a.client, namespace, v1alpha1.ShootCsiDriverLvmResourceName, true); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// previousImages returns the images deployed by the managed resources of the shoot, including the legacy managed
+// resource of previous versions. Versions before the images were recorded in the provider status did not hold back
+// image changes, the images are read from the managed resources so that the first update is held back as well.
+func (a *actuator) previousImages(ctx context.Context, namespace string, desired map[string]string) (map[string]string, error) {
+	objects := []client.Object{}
+	for _, name := range []string{v1alpha1.ShootCsiDriverLvmResourceName, v1alpha1.ShootCsiDriverLvmPluginResourceName, v1alpha1.ShootCsiDriverLvmControllerResourceName} {
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		if err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, managedResource); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get managed resource %q: %w", name, err)
+		}
+
+		for _, ref := range managedResource.Spec.SecretRefs {
+			secret := &corev1.Secret{}
+			if err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+				return nil, fmt.Errorf("failed to get secret of managed resource %q: %w", name, err)
+			}
+
+			for key, data := range secret.Data {
+				obj, _, err := kubernetes.ShootCodec.UniversalDeserializer().Decode(data, nil, nil)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode %s of managed resource %q: %w", key, name, err)
+				}
+				if object, ok := obj.(client.Object); ok {
+					objects = append(objects, object)
+				}
+			}
+		}
+	}
+
+	return deployedImages(objects, desired), nil
+}
+
+// reconcileShootWebhooks deploys the webhooks into the shoot if they are enabled, otherwise they are removed. The
+// webhooks pass the lvmlocal.conf to the provisioner pods, they are only needed if the shoot configures it.
+func (a *actuator) reconcileShootWebhooks(ctx context.Context, namespace string, cluster *extensionscontroller.Cluster, enabled bool) error {
+	var webhookConfigs extensionswebhook.Configs
+	if a.shootWebhookConfig != nil {
+		if configs, ok := a.shootWebhookConfig.Load().(*extensionswebhook.Configs); ok && configs != nil {
+			webhookConfigs = *configs.DeepCopy()
+		}
+	}
+
+	if !enabled || !webhookConfigs.HasWebhookConfig() {
+		if err := managedresources.DeleteForShoot(ctx, a.client, namespace, v1alpha1.ShootCsiDriverLvmWebhooksResourceName); err != nil {
+			return fmt.Errorf("failed to delete managed resource of the shoot webhooks: %w", err)
+		}
+		return nil
+	}
+
+	return extensionsshootwebhook.ReconcileWebhookConfig(ctx, a.client, namespace, v1alpha1.ShootCsiDriverLvmWebhooksResourceName, webhookConfigs, cluster, true)
+}
+
+// managedResourceStatus returns the health of the managed resources of the components
+func (a *actuator) managedResourceStatus(ctx context.Context, namespace string) ([]v1alpha1.ManagedResourceStatus, error) {
+	statuses := []v1alpha1.ManagedResourceStatus{}
+	for _, name := range shootComponentNames {
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		if err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, managedResource); err != nil {
+			return nil, fmt.Errorf("failed to get managed resource %q: %w", name, err)
+		}
+
+		status := v1alpha1.ManagedResourceStatus{Name: name, Healthy: true}
+		if err := health.CheckManagedResource(managedResource); err != nil {
+			status.Healthy = false
+			status.Message = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,390 @@
+package csidriverlvm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	healthcheckconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	resourcesv1alpha1 "github.com/gardener/gardener/pkg/apis/resources/v1alpha1"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/managedresources"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/yaml"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/version"
+)
+
+func TestRenderInputHash(t *testing.T) {
+	input := renderInput{
+		Config: &v1alpha1.CsiDriverLvmConfig{HostWritePath: ptr.To("/etc/lvm")},
+		Images: map[string]string{imageCsiDriverLvm: "ghcr.io/metal-stack/csi-driver-lvm:v0.6.0"},
+	}
+
+	hash, err := input.hash()
+	require.NoError(t, err)
+
+	same, err := input.hash()
+	require.NoError(t, err)
+	assert.Equal(t, hash, same)
+
+	changed := input
+	changed.Images = map[string]string{imageCsiDriverLvm: "ghcr.io/metal-stack/csi-driver-lvm:v0.7.0"}
+	changedHash, err := changed.hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+
+	defer func(previous string) { version.Version = previous }(version.Version)
+	version.Version = "v0.0.0-test"
+	versionHash, err := input.hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, versionHash)
+
+	// changes of the shoot chart invalidate the hash although the version may be the same
+	defer func(previous func() (string, error)) { shootChartChecksum = previous }(shootChartChecksum)
+	shootChartChecksum = func() (string, error) { return "changed", nil }
+	chartHash, err := input.hash()
+	require.NoError(t, err)
+	assert.NotEqual(t, versionHash, chartHash)
+}
+
+func TestReconcileSkipsUnchangedManagedResource(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	reconcile := func() *resourcesv1alpha1.ManagedResource {
+		ex := &extensionsv1alpha1.Extension{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+		require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmPluginResourceName}, managedResource))
+		return managedResource
+	}
+
+	created := reconcile()
+	assert.NotEmpty(t, created.Annotations[configHashAnnotation])
+
+	unchanged := reconcile()
+	assert.Equal(t, created.ResourceVersion, unchanged.ResourceVersion)
+	assert.Equal(t, created.Spec.SecretRefs, unchanged.Spec.SecretRefs)
+
+	// settings of the controller configuration which do not affect the rendered objects keep the hash
+	a.config.RolloutPolicy = &config.RolloutPolicy{Percentage: ptr.To(int32(100))}
+	a.config.HealthCheckConfig = &healthcheckconfig.HealthCheckConfig{SyncPeriod: metav1.Duration{Duration: time.Minute}}
+	a.config.AllowGeometry = ptr.To(true)
+	reconfigured := reconcile()
+	assert.Equal(t, created.ResourceVersion, reconfigured.ResourceVersion)
+
+	defer func(previous string) { version.Version = previous }(version.Version)
+	version.Version = "v0.0.0-test"
+
+	updated := reconcile()
+	assert.NotEqual(t, created.Annotations[configHashAnnotation], updated.Annotations[configHashAnnotation])
+}
+
+func TestReconcileChangedStorageClassParameter(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+	a.config.AllowedFsTypes = []string{"ext4", "xfs"}
+
+	reconcile := func(providerConfig string) *storagev1.StorageClass {
+		ex := &extensionsv1alpha1.Extension{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+		if providerConfig != "" {
+			ex.Spec.ProviderConfig = &runtime.RawExtension{Raw: []byte(providerConfig)}
+			require.NoError(t, c.Update(ctx, ex))
+		}
+		require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+		managedResource := &resourcesv1alpha1.ManagedResource{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmStorageClassesResourceName}, managedResource))
+		require.Len(t, managedResource.Spec.SecretRefs, 1)
+		secret := &corev1.Secret{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: managedResource.Spec.SecretRefs[0].Name}, secret))
+
+		sc := &storagev1.StorageClass{}
+		require.NoError(t, yaml.Unmarshal(secret.Data["storageclass____csi-driver-lvm-linear.yaml"], sc))
+		return sc
+	}
+
+	existing := reconcile("")
+	assert.NotContains(t, existing.Parameters, fsTypeParameter)
+
+	// the parameters of an existing class are immutable, the resource manager has to recreate the class
+	changed := reconcile(`{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","storageClasses":[{"name":"csi-driver-lvm-linear","fsType":"xfs"}]}`)
+	assert.Equal(t, "xfs", changed.Parameters[fsTypeParameter])
+	assert.Equal(t, "true", changed.Annotations[resourcesv1alpha1.DeleteOnInvalidUpdate])
+}
+
+func TestReconcileMigratesLegacyManagedResource(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	legacySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "managedresource-" + v1alpha1.ShootCsiDriverLvmResourceName},
+	}
+	legacy := &resourcesv1alpha1.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmResourceName},
+		Spec: resourcesv1alpha1.ManagedResourceSpec{
+			SecretRefs: []corev1.LocalObjectReference{{Name: legacySecret.Name}},
+		},
+	}
+	require.NoError(t, c.Create(ctx, legacySecret))
+	require.NoError(t, c.Create(ctx, legacy))
+
+	// the objects must be kept in the shoot when the legacy managed resource is deleted
+	keptObjects := false
+	a.client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if managedResource, ok := obj.(*resourcesv1alpha1.ManagedResource); ok && managedResource.Name == v1alpha1.ShootCsiDriverLvmResourceName {
+				current := &resourcesv1alpha1.ManagedResource{}
+				require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(obj), current))
+				keptObjects = ptr.Deref(current.Spec.KeepObjects, false)
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	ex := &extensionsv1alpha1.Extension{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+	require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+	assert.True(t, keptObjects)
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(legacy), &resourcesv1alpha1.ManagedResource{})))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKeyFromObject(legacySecret), &corev1.Secret{})))
+
+	for _, name := range shootComponentNames {
+		assert.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: name}, &resourcesv1alpha1.ManagedResource{}), name)
+	}
+
+	status, err := a.providerStatus(ex)
+	require.NoError(t, err)
+	require.Len(t, status.ManagedResources, len(shootComponentNames))
+	for _, managedResource := range status.ManagedResources {
+		// the resource manager has not reported any conditions yet
+		assert.False(t, managedResource.Healthy)
+		assert.NotEmpty(t, managedResource.Message)
+	}
+}
+
+func TestPreviousImages(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	// the legacy managed resource deployed the plugin with the images of a previous version
+	plugin := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: shootNamespace, Name: "csi-driver-lvm-plugin"},
+		Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "csi-node-driver-registrar", Image: "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.10.0"},
+				{Name: "csi-driver-lvm-plugin", Image: "ghcr.io/metal-stack/csi-driver-lvm:v0.5.2", Args: []string{"--provisionerimage=ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.5.2"}},
+				{Name: "sidecar", Image: "example.com/sidecar:v1.0.0"},
+			},
+		}}},
+	}
+	data, err := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer).AddAllAndSerialize(plugin)
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "managedresource-" + v1alpha1.ShootCsiDriverLvmResourceName},
+		Data:       data,
+	}
+	require.NoError(t, c.Create(ctx, secret))
+	require.NoError(t, c.Create(ctx, &resourcesv1alpha1.ManagedResource{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmResourceName},
+		Spec: resourcesv1alpha1.ManagedResourceSpec{
+			SecretRefs: []corev1.LocalObjectReference{{Name: secret.Name}},
+		},
+	}))
+
+	images, err := a.previousImages(ctx, testNamespace, goldenImages)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		imageCsiDriverLvm:            "ghcr.io/metal-stack/csi-driver-lvm:v0.5.2",
+		imageCsiDriverLvmProvisioner: "ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.5.2",
+		imageCsiNodeDriverRegistrar:  "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.10.0",
+	}, images)
+
+	// without any managed resource there is nothing to hold back
+	a, _ = newTestActuator(t, testNamespace)
+	images, err = a.previousImages(ctx, testNamespace, goldenImages)
+	require.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestReconcileShootWebhooks(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	a.shootWebhookConfig = &atomic.Value{}
+	a.shootWebhookConfig.Store(&extensionswebhook.Configs{
+		MutatingWebhookConfig: &admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "gardener-extension-" + Type + "-shoot"},
+			Webhooks:   []admissionregistrationv1.MutatingWebhook{{Name: "csi-driver-lvm-provisioner.metal.extensions.gardener.cloud"}},
+		},
+	})
+
+	reconcile := func(providerConfig string) error {
+		ex := &extensionsv1alpha1.Extension{}
+		require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+		ex.Spec.ProviderConfig = &runtime.RawExtension{Raw: []byte(providerConfig)}
+		require.NoError(t, c.Update(ctx, ex))
+		require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+		return c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: v1alpha1.ShootCsiDriverLvmWebhooksResourceName}, &resourcesv1alpha1.ManagedResource{})
+	}
+
+	// the provisioner pods only need the webhook if the shoot configures an lvmlocal.conf
+	require.True(t, apierrors.IsNotFound(reconcile(`{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig"}`)))
+	require.NoError(t, reconcile(`{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","lvmConfig":{"issueDiscards":true}}`))
+	require.True(t, apierrors.IsNotFound(reconcile(`{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig"}`)))
+}
+
+func TestDeleteManagedResources(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	ex := &extensionsv1alpha1.Extension{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+	require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+
+	deleted := []string{}
+	a.client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			if _, ok := obj.(*resourcesv1alpha1.ManagedResource); ok {
+				deleted = append(deleted, obj.GetName())
+			}
+			return c.Delete(ctx, obj, opts...)
+		},
+	})
+
+	_, err := a.encryptionChecksumKey(ctx, testNamespace)
+	require.NoError(t, err)
+
+	require.NoError(t, a.Delete(ctx, logr.Discard(), ex))
+	assert.True(t, apierrors.IsNotFound(c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: encryptionChecksumKeyName}, &corev1.Secret{})))
+	assert.Equal(t, []string{
+		v1alpha1.ShootCsiDriverLvmStorageClassesResourceName,
+		v1alpha1.ShootCsiDriverLvmControllerResourceName,
+		v1alpha1.ShootCsiDriverLvmPluginResourceName,
+	}, deleted)
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	const shoots = 100
+
+	namespaces := []string{}
+	for i := range shoots {
+		namespaces = append(namespaces, fmt.Sprintf("shoot--test--test-%d", i))
+	}
+
+	reconcileAll := func(b *testing.B, a *actuator, c client.Client) {
+		for _, namespace := range namespaces {
+			ex := &extensionsv1alpha1.Extension{}
+			require.NoError(b, c.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: Type}, ex))
+			require.NoError(b, a.Reconcile(context.Background(), logr.Discard(), ex))
+		}
+	}
+
+	b.Run("unchanged", func(b *testing.B) {
+		a, c := newTestActuator(b, namespaces...)
+		reconcileAll(b, a, c)
+
+		b.ResetTimer()
+		for range b.N {
+			reconcileAll(b, a, c)
+		}
+	})
+
+	b.Run("changed", func(b *testing.B) {
+		a, c := newTestActuator(b, namespaces...)
+
+		defer func(previous string) { version.Version = previous }(version.Version)
+
+		b.ResetTimer()
+		for i := range b.N {
+			// every new version of the extension invalidates the hashes of all shoots
+			version.Version = fmt.Sprintf("v0.0.%d", i)
+			reconcileAll(b, a, c)
+		}
+	})
+}
+
+// newTestActuator returns an actuator for a seed with a shoot in each of the given namespaces, the shoot clients are
+// fake clients without any objects
+func newTestActuator(t testing.TB, namespaces ...string) (*actuator, client.Client) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, extensionscontroller.AddToScheme(scheme))
+	require.NoError(t, resourcesv1alpha1.AddToScheme(scheme))
+	require.NoError(t, install.AddToScheme(scheme))
+
+	shoot := &gardencorev1beta1.Shoot{
+		TypeMeta: metav1.TypeMeta{APIVersion: gardencorev1beta1.SchemeGroupVersion.String(), Kind: "Shoot"},
+		Spec: gardencorev1beta1.ShootSpec{
+			Provider: gardencorev1beta1.Provider{Workers: []gardencorev1beta1.Worker{{Name: "default"}}},
+		},
+	}
+	rawShoot, err := json.Marshal(shoot)
+	require.NoError(t, err)
+
+	objects := []client.Object{}
+	extensions := []client.Object{}
+	for _, namespace := range namespaces {
+		ex := &extensionsv1alpha1.Extension{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: Type},
+		}
+		extensions = append(extensions, ex)
+		objects = append(objects,
+			&extensionsv1alpha1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: namespace},
+				Spec:       extensionsv1alpha1.ClusterSpec{Shoot: runtime.RawExtension{Raw: rawShoot}},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: v1beta1constants.SecretNameGardenerInternal},
+			},
+			ex,
+		)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(extensions...).Build()
+
+	shootClients := newShootClientCache(c)
+	shootClients.newClient = func(secret *corev1.Secret) (client.Client, *http.Client, error) {
+		return fake.NewClientBuilder().Build(), nil, nil
+	}
+
+	return &actuator{
+		client:        c,
+		decoder:       serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+		config:        config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")},
+		shootClients:  shootClients,
+		chartRenderer: newChartRenderer(),
+	}, c
+}
@@ -0,0 +1,208 @@
+package csidriverlvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+// immutablePaths identify the patched objects, they cannot be patched even if the operator allows a parent field
+var immutablePaths = [][]string{
+	{"apiVersion"},
+	{"kind"},
+	{"metadata", "name"},
+	{"metadata", "namespace"},
+}
+
+// allowedPatchPaths returns the fields of the objects of the kind which the operator allows to patch
+func allowedPatchPaths(allowedPatches []config.AllowedPatch, kind string) []string {
+	var paths []string
+	for _, allowed := range allowedPatches {
+		if allowed.Kind == kind {
+			paths = append(paths, allowed.Paths...)
+		}
+	}
+	return paths
+}
+
+// applyPatches applies the patches of the shoot to the objects of the components, every patch has to match an object
+// and may only change the fields allowed by the operator
+func applyPatches(components []shootComponent, patches []v1alpha1.Patch, allowedPatches []config.AllowedPatch) error {
+	for _, patch := range patches {
+		found := false
+		for _, component := range components {
+			for i, obj := range component.objects {
+				gvk, err := apiutil.GVKForObject(obj, kubernetes.ShootScheme)
+				if err != nil {
+					return fmt.Errorf("failed to get kind of %q: %w", obj.GetName(), err)
+				}
+				if gvk.Kind != patch.Kind || obj.GetName() != patch.Name {
+					continue
+				}
+
+				patched, err := patchObject(obj, gvk, patch, allowedPatchPaths(allowedPatches, patch.Kind))
+				if err != nil {
+					return fmt.Errorf("failed to patch %s %q: %w", patch.Kind, patch.Name, err)
+				}
+
+				component.objects[i] = patched
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("patched %s %q is not deployed into the shoot", patch.Kind, patch.Name)
+		}
+	}
+
+	return nil
+}
+
+// patchObject returns a patched copy of the object, the patch is rejected if it changes fields which are not allowed
+func patchObject(obj client.Object, gvk schema.GroupVersionKind, patch v1alpha1.Patch, allowedPaths []string) (client.Object, error) {
+	// the encryption secrets are built in Go and do not set their kind, unlike the objects rendered from the chart, it
+	// is set so that the patch sees the same object as the shoot
+	obj = obj.DeepCopyObject().(client.Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch.Patch))
+	if err != nil {
+		return nil, fmt.Errorf("patch is invalid: %w", err)
+	}
+
+	var patchedJSON []byte
+	switch ptr.Deref(patch.Type, v1alpha1.PatchTypeStrategicMerge) {
+	case v1alpha1.PatchTypeJSON:
+		jsonPatch, err := jsonpatch.DecodePatch(patchJSON)
+		if err != nil {
+			return nil, fmt.Errorf("patch is invalid: %w", err)
+		}
+		patchedJSON, err = jsonPatch.Apply(original)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		patchedJSON, err = strategicpatch.StrategicMergePatch(original, patchJSON, obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var before, after any
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patchedJSON, &after); err != nil {
+		return nil, err
+	}
+
+	for _, path := range changedPaths(nil, before, after) {
+		if slices.ContainsFunc(immutablePaths, func(immutable []string) bool { return matchesPath(path, immutable) }) {
+			return nil, fmt.Errorf("field %s cannot be patched", formatPath(path))
+		}
+		if !slices.ContainsFunc(allowedPaths, func(allowed string) bool { return matchesPath(path, parsePath(allowed)) }) {
+			return nil, fmt.Errorf("field %s is not allowed to be patched", formatPath(path))
+		}
+	}
+
+	patched, err := kubernetes.ShootScheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patchedJSON, patched); err != nil {
+		return nil, fmt.Errorf("patched object is invalid: %w", err)
+	}
+
+	return patched.(client.Object), nil
+}
+
+// changedPaths returns the paths of the fields which differ between the objects, fields which are added or removed
+// are compared to an empty object so that only the changed fields below them are returned
+func changedPaths(path []string, before, after any) [][]string {
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if (beforeIsMap || before == nil) && (afterIsMap || after == nil) && (beforeIsMap || afterIsMap) {
+		keys := slices.Concat(slices.Collect(maps.Keys(beforeMap)), slices.Collect(maps.Keys(afterMap)))
+		slices.Sort(keys)
+
+		var paths [][]string
+		for _, key := range slices.Compact(keys) {
+			paths = append(paths, changedPaths(append(slices.Clone(path), key), beforeMap[key], afterMap[key])...)
+		}
+		return paths
+	}
+
+	// list entries are compared by their index, a list with added or removed entries is changed as a whole
+	beforeList, beforeIsList := before.([]any)
+	afterList, afterIsList := after.([]any)
+	if beforeIsList && afterIsList && len(beforeList) == len(afterList) {
+		var paths [][]string
+		for i := range beforeList {
+			paths = append(paths, changedPaths(append(slices.Clone(path), strconv.Itoa(i)), beforeList[i], afterList[i])...)
+		}
+		return paths
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return [][]string{path}
+}
+
+// matchesPath returns true if the path is the pattern or a field below it, "*" in the pattern matches any segment
+func matchesPath(path, pattern []string) bool {
+	if len(path) < len(pattern) {
+		return false
+	}
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePath splits a JSON pointer (RFC 6901) into its segments
+func parsePath(pointer string) []string {
+	pointer = strings.TrimPrefix(pointer, "/")
+	if pointer == "" {
+		return nil
+	}
+
+	segments := strings.Split(pointer, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+	}
+	return segments
+}
+
+// formatPath returns the JSON pointer (RFC 6901) of the path
+func formatPath(path []string) string {
+	var pointer strings.Builder
+	for _, segment := range path {
+		pointer.WriteString("/")
+		pointer.WriteString(strings.ReplaceAll(strings.ReplaceAll(segment, "~", "~0"), "/", "~1"))
+	}
+	return pointer.String()
+}
@@ -0,0 +1,151 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+func TestApplyPatches(t *testing.T) {
+	tt := []struct {
+		desc    string
+		patches []v1alpha1.Patch
+		allowed []config.AllowedPatch
+		wantErr string
+		check   func(t *testing.T, daemonSet *appsv1.DaemonSet)
+	}{
+		{
+			desc:  "test without patches",
+			check: func(t *testing.T, daemonSet *appsv1.DaemonSet) { assert.Empty(t, daemonSet.Annotations) },
+		},
+		{
+			desc: "test strategic merge patch of annotations",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: "metadata:\n  annotations:\n    example.com/team: storage\n"},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata/annotations/example.com~1team"}}},
+			check: func(t *testing.T, daemonSet *appsv1.DaemonSet) {
+				assert.Equal(t, map[string]string{"example.com/team": "storage"}, daemonSet.Annotations)
+			},
+		},
+		{
+			desc: "test strategic merge patch of the env of a container",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: `{"spec": {"template": {"spec": {"containers": [{"name": "csi-driver-lvm-plugin", "env": [{"name": "LVM_DEBUG", "value": "1"}]}]}}}}`},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/spec/template/spec/containers/*/env"}}},
+			check: func(t *testing.T, daemonSet *appsv1.DaemonSet) {
+				containers := daemonSet.Spec.Template.Spec.Containers
+				require.Len(t, containers, 3)
+				assert.Equal(t, "csi-driver-lvm-plugin", containers[1].Name)
+				assert.Contains(t, containers[1].Env, corev1.EnvVar{Name: "LVM_DEBUG", Value: "1"})
+				assert.Contains(t, containers[1].Args, "--vgname=csi-lvm")
+			},
+		},
+		{
+			desc: "test json patch of a probe",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Type: ptr.To(v1alpha1.PatchTypeJSON), Patch: `[{"op": "replace", "path": "/spec/template/spec/containers/1/livenessProbe/timeoutSeconds", "value": 10}]`},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/spec/template/spec/containers/*/livenessProbe"}}},
+			check: func(t *testing.T, daemonSet *appsv1.DaemonSet) {
+				assert.Equal(t, int32(10), daemonSet.Spec.Template.Spec.Containers[1].LivenessProbe.TimeoutSeconds)
+			},
+		},
+		{
+			desc: "test patch of field which is not allowed",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Type: ptr.To(v1alpha1.PatchTypeJSON), Patch: `[{"op": "replace", "path": "/spec/template/spec/containers/1/image", "value": "example.com/csi-driver-lvm:latest"}]`},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata/annotations", "/spec/template/spec/containers/*/env"}}},
+			wantErr: `field /spec/template/spec/containers/1/image is not allowed to be patched`,
+		},
+		{
+			desc: "test patch adding a container",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: `{"spec": {"template": {"spec": {"containers": [{"name": "debug", "image": "busybox"}]}}}}`},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/spec/template/spec/containers/*"}}},
+			wantErr: `field /spec/template/spec/containers is not allowed to be patched`,
+		},
+		{
+			desc: "test patch of the name",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: "metadata:\n  name: renamed\n"},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata"}}},
+			wantErr: `field /metadata/name cannot be patched`,
+		},
+		{
+			desc: "test patch of object which is not deployed",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "unknown", Patch: "metadata: {}"},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata"}}},
+			wantErr: `patched DaemonSet "unknown" is not deployed into the shoot`,
+		},
+		{
+			desc: "test invalid json patch",
+			patches: []v1alpha1.Patch{
+				{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Type: ptr.To(v1alpha1.PatchTypeJSON), Patch: `{"op": "add"}`},
+			},
+			allowed: []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata"}}},
+			wantErr: `patch is invalid`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			a, csidriverlvmConfig, groups := prepareGoldenVariant(t, goldenVariants()[0])
+			a.config.AllowedPatches = tc.allowed
+			csidriverlvmConfig.Patches = tc.patches
+
+			components, err := a.shootComponents(csidriverlvmConfig, groups, goldenImages, false, false, nil, nil)
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var daemonSet *appsv1.DaemonSet
+			for _, component := range components {
+				for _, obj := range component.objects {
+					if ds, ok := obj.(*appsv1.DaemonSet); ok && ds.Name == "csi-driver-lvm-plugin" {
+						daemonSet = ds
+					}
+				}
+			}
+			require.NotNil(t, daemonSet)
+			tc.check(t, daemonSet)
+		})
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	before := map[string]any{
+		"metadata": map[string]any{"name": "plugin"},
+		"spec":     map[string]any{"args": []any{"--v=5"}, "replicas": float64(1)},
+	}
+	after := map[string]any{
+		"metadata": map[string]any{"name": "plugin", "annotations": map[string]any{"a/b": "c"}},
+		"spec":     map[string]any{"args": []any{"--v=2"}},
+	}
+
+	paths := []string{}
+	for _, path := range changedPaths(nil, before, after) {
+		paths = append(paths, formatPath(path))
+	}
+	assert.Equal(t, []string{"/metadata/annotations/a~1b", "/spec/args/0", "/spec/replicas"}, paths)
+
+	assert.True(t, matchesPath([]string{"metadata", "annotations", "a/b"}, parsePath("/metadata/annotations/a~1b")))
+	assert.True(t, matchesPath([]string{"spec", "containers", "2", "env"}, parsePath("/spec/containers/*")))
+	assert.False(t, matchesPath([]string{"spec", "containers"}, parsePath("/spec/containers/*")))
+}
@@ -0,0 +1,57 @@
+package csidriverlvm
+
+import (
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+)
+
+const defaultLogLevel int32 = 5
+
+// applyPurposeProfile defaults the settings of the configuration which are not configured in the shoot from the
+// purpose profile of the operator matching the purpose of the shoot
+func applyPurposeProfile(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, cluster *extensionscontroller.Cluster) {
+	if cluster == nil || cluster.Shoot == nil || cluster.Shoot.Spec.Purpose == nil {
+		return
+	}
+
+	for _, profile := range controllerConfig.PurposeProfiles {
+		if profile.Purpose != string(*cluster.Shoot.Spec.Purpose) {
+			continue
+		}
+
+		if csidriverlvmConfig.ReclaimPolicy == nil {
+			csidriverlvmConfig.ReclaimPolicy = profile.ReclaimPolicy
+		}
+		if csidriverlvmConfig.HighAvailability == nil {
+			csidriverlvmConfig.HighAvailability = profile.HighAvailability
+		}
+		if csidriverlvmConfig.LogLevel == nil {
+			csidriverlvmConfig.LogLevel = profile.LogLevel
+		}
+		if csidriverlvmConfig.DefaultStorageClass == nil {
+			csidriverlvmConfig.DefaultStorageClass = profile.DefaultStorageClass
+		}
+		if csidriverlvmConfig.DeletionPolicy == nil {
+			csidriverlvmConfig.DeletionPolicy = profile.DeletionPolicy
+		}
+		return
+	}
+}
+
+// logLevel returns the log level of the csi sidecars
+func logLevel(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) int32 {
+	return ptr.Deref(csidriverlvmConfig.LogLevel, defaultLogLevel)
+}
+
+// reclaimPolicy returns the reclaim policy of the storage classes
+func reclaimPolicy(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) corev1.PersistentVolumeReclaimPolicy {
+	return ptr.Deref(csidriverlvmConfig.ReclaimPolicy, corev1.PersistentVolumeReclaimDelete)
+}
+
+// keepObjects returns true if the objects deployed into the shoot are kept when the extension is removed
+func keepObjects(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) bool {
+	return ptr.Deref(csidriverlvmConfig.DeletionPolicy, v1alpha1.DeletionPolicyDelete) == v1alpha1.DeletionPolicyRetain
+}
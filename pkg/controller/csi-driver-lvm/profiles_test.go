@@ -0,0 +1,129 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestApplyPurposeProfile(t *testing.T) {
+	controllerConfig := config.ControllerConfiguration{
+		PurposeProfiles: []config.PurposeProfile{
+			{
+				Purpose:             "production",
+				ReclaimPolicy:       ptr.To(corev1.PersistentVolumeReclaimRetain),
+				HighAvailability:    ptr.To(true),
+				LogLevel:            ptr.To(int32(2)),
+				DefaultStorageClass: ptr.To("csi-driver-lvm-mirror"),
+				DeletionPolicy:      ptr.To(v1alpha1.DeletionPolicyRetain),
+			},
+			{
+				Purpose:          "evaluation",
+				HighAvailability: ptr.To(false),
+				LogLevel:         ptr.To(int32(1)),
+			},
+		},
+	}
+
+	tt := []struct {
+		desc    string
+		config  *v1alpha1.CsiDriverLvmConfig
+		purpose *gardencorev1beta1.ShootPurpose
+		want    *v1alpha1.CsiDriverLvmConfig
+	}{
+		{
+			desc:    "test production profile",
+			config:  &v1alpha1.CsiDriverLvmConfig{},
+			purpose: ptr.To(gardencorev1beta1.ShootPurposeProduction),
+			want: &v1alpha1.CsiDriverLvmConfig{
+				ReclaimPolicy:       ptr.To(corev1.PersistentVolumeReclaimRetain),
+				HighAvailability:    ptr.To(true),
+				LogLevel:            ptr.To(int32(2)),
+				DefaultStorageClass: ptr.To("csi-driver-lvm-mirror"),
+				DeletionPolicy:      ptr.To(v1alpha1.DeletionPolicyRetain),
+			},
+		},
+		{
+			desc: "test explicit settings override the profile",
+			config: &v1alpha1.CsiDriverLvmConfig{
+				ReclaimPolicy:  ptr.To(corev1.PersistentVolumeReclaimDelete),
+				LogLevel:       ptr.To(int32(5)),
+				DeletionPolicy: ptr.To(v1alpha1.DeletionPolicyDelete),
+			},
+			purpose: ptr.To(gardencorev1beta1.ShootPurposeProduction),
+			want: &v1alpha1.CsiDriverLvmConfig{
+				ReclaimPolicy:       ptr.To(corev1.PersistentVolumeReclaimDelete),
+				HighAvailability:    ptr.To(true),
+				LogLevel:            ptr.To(int32(5)),
+				DefaultStorageClass: ptr.To("csi-driver-lvm-mirror"),
+				DeletionPolicy:      ptr.To(v1alpha1.DeletionPolicyDelete),
+			},
+		},
+		{
+			desc:    "test evaluation profile",
+			config:  &v1alpha1.CsiDriverLvmConfig{},
+			purpose: ptr.To(gardencorev1beta1.ShootPurposeEvaluation),
+			want: &v1alpha1.CsiDriverLvmConfig{
+				HighAvailability: ptr.To(false),
+				LogLevel:         ptr.To(int32(1)),
+			},
+		},
+		{
+			desc:    "test purpose without profile",
+			config:  &v1alpha1.CsiDriverLvmConfig{},
+			purpose: ptr.To(gardencorev1beta1.ShootPurposeDevelopment),
+			want:    &v1alpha1.CsiDriverLvmConfig{},
+		},
+		{
+			desc:   "test shoot without purpose",
+			config: &v1alpha1.CsiDriverLvmConfig{},
+			want:   &v1alpha1.CsiDriverLvmConfig{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			cluster := &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{Purpose: tc.purpose}}}
+			applyPurposeProfile(tc.config, controllerConfig, cluster)
+			assert.Equal(t, tc.want, tc.config)
+		})
+	}
+}
+
+func TestStorageClassPolicies(t *testing.T) {
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{
+		ReclaimPolicy:       ptr.To(corev1.PersistentVolumeReclaimRetain),
+		DefaultStorageClass: ptr.To("csi-driver-lvm-mirror"),
+	}
+
+	csidriverlvmConfig.ConfigureDefaults(ptr.To("/etc/lvm"), ptr.To("/dev/nvme[0-9]n[0-9]"))
+
+	a := &actuator{chartRenderer: newChartRenderer()}
+	objects, err := a.renderShootChart(csidriverlvmConfig, pluginGroups(csidriverlvmConfig, config.ControllerConfiguration{}, nil), goldenImages, false, false, nil)
+	require.NoError(t, err)
+
+	storageClasses := objects[v1alpha1.ShootCsiDriverLvmStorageClassesResourceName]
+	require.Len(t, storageClasses, len(defaultStorageClasses))
+	for _, object := range storageClasses {
+		sc := object.(*storagev1.StorageClass)
+		assert.Equal(t, ptr.To(corev1.PersistentVolumeReclaimRetain), sc.ReclaimPolicy)
+		if sc.Name == "csi-driver-lvm-mirror" {
+			assert.Equal(t, "true", sc.Annotations["storageclass.kubernetes.io/is-default-class"])
+		} else {
+			assert.NotContains(t, sc.Annotations, "storageclass.kubernetes.io/is-default-class")
+		}
+	}
+
+	assert.Equal(t, int32(5), logLevel(&v1alpha1.CsiDriverLvmConfig{}))
+	assert.Equal(t, int32(2), logLevel(&v1alpha1.CsiDriverLvmConfig{LogLevel: ptr.To(int32(2))}))
+	assert.False(t, keepObjects(&v1alpha1.CsiDriverLvmConfig{}))
+	assert.True(t, keepObjects(&v1alpha1.CsiDriverLvmConfig{DeletionPolicy: ptr.To(v1alpha1.DeletionPolicyRetain)}))
+}
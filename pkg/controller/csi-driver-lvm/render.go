@@ -0,0 +1,114 @@
+package csidriverlvm
+
+import (
+	"context"
+	"fmt"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils/managedresources"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+const (
+	// placeholderPassphrase replaces the encryption keys of the storage classes when rendering offline
+	placeholderPassphrase = "<passphrase of the referenced secret>"
+	// placeholderChecksumKey replaces the key of the checksums of the encryption keys kept in the seed
+	placeholderChecksumKey = "<checksum key of the seed>"
+)
+
+// RenderOptions contains the inputs to render the objects deployed into a shoot
+type RenderOptions struct {
+	// Config is the configuration of the shoot
+	Config *v1alpha1.CsiDriverLvmConfig
+	// ControllerConfig is the configuration of the operator
+	ControllerConfig config.ControllerConfiguration
+	// Cluster is the cluster of the shoot, it is optional
+	Cluster *extensionscontroller.Cluster
+}
+
+// RenderedManagedResource contains the serialized objects of a managed resource deployed into the shoot
+type RenderedManagedResource struct {
+	// Name is the name of the managed resource
+	Name string
+	// Data contains the serialized objects by their file name
+	Data map[string][]byte
+}
+
+// Render validates the configuration the same way as the actuator and returns the managed resources deployed into the
+// shoot in the order they are deployed. It runs offline: the images of the image vector are used and the encryption
+// keys referenced by the storage classes are replaced by a placeholder.
+func Render(log logr.Logger, opts RenderOptions) ([]RenderedManagedResource, error) {
+	components, err := renderComponents(context.Background(), log, opts, placeholderSecretReader{})
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := []RenderedManagedResource{}
+	for _, component := range components {
+		data, err := managedresources.NewRegistry(kubernetes.ShootScheme, kubernetes.ShootCodec, kubernetes.ShootSerializer).AddAllAndSerialize(component.objects...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize managed resource %q: %w", component.managedResourceName, err)
+		}
+		rendered = append(rendered, RenderedManagedResource{Name: component.managedResourceName, Data: data})
+	}
+
+	return rendered, nil
+}
+
+// renderComponents validates the configuration the same way as the actuator and renders the components with the
+// images of the image vector, the encryption keys are read with the given reader
+func renderComponents(ctx context.Context, log logr.Logger, opts RenderOptions, reader client.Reader) ([]shootComponent, error) {
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	if opts.Config != nil {
+		csidriverlvmConfig = opts.Config.DeepCopy()
+	}
+	cluster := opts.Cluster
+
+	if err := validateCluster(cluster); err != nil {
+		return nil, err
+	}
+
+	images, err := findImages()
+	if err != nil {
+		return nil, err
+	}
+
+	workerPools, err := prepareConfig(log, csidriverlvmConfig, opts.ControllerConfig, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionSecrets, encryptionChecksums, err := encryptionSecrets(ctx, reader, "", cluster, csidriverlvmConfig, []byte(placeholderChecksumKey))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &actuator{config: opts.ControllerConfig, chartRenderer: newChartRenderer()}
+	return a.shootComponents(csidriverlvmConfig, pluginGroups(csidriverlvmConfig, opts.ControllerConfig, workerPools), images, isHighAvailability(csidriverlvmConfig, cluster), isMultiZonal(cluster), encryptionChecksums, encryptionObjects(encryptionSecrets))
+}
+
+// placeholderSecretReader returns a secret with a placeholder passphrase for every secret, the secrets of the seed
+// are not available offline
+type placeholderSecretReader struct{}
+
+func (placeholderSecretReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	secret, ok := obj.(*corev1.Secret)
+	if !ok {
+		return fmt.Errorf("unable to get %T %s offline", obj, key)
+	}
+
+	secret.Name = key.Name
+	secret.Namespace = key.Namespace
+	secret.Data = map[string][]byte{encryptionPassphraseKey: []byte(placeholderPassphrase)}
+	return nil
+}
+
+func (placeholderSecretReader) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	return fmt.Errorf("unable to list %T offline", list)
+}
@@ -0,0 +1,97 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+func TestRender(t *testing.T) {
+	controllerConfig := config.ControllerConfiguration{DefaultHostWritePath: ptr.To("/etc/lvm"), DefaultDevicePattern: ptr.To("/dev/nvme[0-9]n[0-9]")}
+	shoot := func(workers ...gardencorev1beta1.Worker) *extensionscontroller.Cluster {
+		return &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{
+			Spec: gardencorev1beta1.ShootSpec{
+				Provider: gardencorev1beta1.Provider{Workers: workers},
+				Resources: []gardencorev1beta1.NamedResourceReference{
+					{Name: "luks", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "luks", APIVersion: "v1"}},
+				},
+			},
+		}}
+	}
+
+	tt := []struct {
+		desc      string
+		config    *v1alpha1.CsiDriverLvmConfig
+		cluster   *extensionscontroller.Cluster
+		wantFiles map[string][]string
+		wantErr   string
+	}{
+		{
+			desc: "test without cluster",
+			wantFiles: map[string][]string{
+				v1alpha1.ShootCsiDriverLvmPluginResourceName:         {"daemonset__kube-system__csi-driver-lvm-plugin.yaml"},
+				v1alpha1.ShootCsiDriverLvmControllerResourceName:     {"statefulset__kube-system__csi-driver-lvm-controller.yaml"},
+				v1alpha1.ShootCsiDriverLvmStorageClassesResourceName: {"storageclass____csi-lvm.yaml"},
+			},
+		},
+		{
+			// encryption has to be allowed by the operator
+			desc: "test encrypted storage class",
+			config: &v1alpha1.CsiDriverLvmConfig{StorageClasses: []v1alpha1.StorageClass{
+				{Name: "encrypted", Type: ptr.To(v1alpha1.VolumeTypeLinear), Encryption: &v1alpha1.Encryption{SecretResourceName: "luks"}},
+			}},
+			cluster: shoot(gardencorev1beta1.Worker{Name: "default"}),
+			wantErr: `encryption of storage class "encrypted" is not allowed`,
+		},
+		{
+			desc:    "test workerless shoot",
+			cluster: shoot(),
+			wantErr: "not applicable for workerless shoots",
+		},
+		{
+			desc:    "test invalid configuration",
+			config:  &v1alpha1.CsiDriverLvmConfig{HostWritePath: ptr.To("relative")},
+			wantErr: "invalid csi-driver-lvm configuration",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			config := tc.config.DeepCopy()
+
+			got, err := Render(logr.Discard(), RenderOptions{Config: config, ControllerConfig: controllerConfig, Cluster: tc.cluster})
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			names := []string{}
+			files := map[string]map[string][]byte{}
+			for _, managedResource := range got {
+				names = append(names, managedResource.Name)
+				files[managedResource.Name] = managedResource.Data
+			}
+			assert.Equal(t, shootComponentNames, names)
+
+			for name, wantFiles := range tc.wantFiles {
+				for _, file := range wantFiles {
+					assert.Contains(t, files[name], file)
+				}
+			}
+
+			// the configuration of the caller is not defaulted
+			assert.Equal(t, tc.config, config)
+		})
+	}
+}
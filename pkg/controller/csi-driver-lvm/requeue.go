@@ -0,0 +1,64 @@
+package csidriverlvm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// requeues records when extensions have to be reconciled again after a successful reconciliation. The extension
+// reconciler of gardener only requeues after errors, which would mark the extension as failed.
+type requeues struct {
+	mu    sync.Mutex
+	after map[types.NamespacedName]time.Duration
+}
+
+func newRequeues() *requeues {
+	return &requeues{after: map[types.NamespacedName]time.Duration{}}
+}
+
+// set requests a reconciliation of the extension after the given duration, it is a no-op for a nil receiver
+func (r *requeues) set(key types.NamespacedName, after time.Duration) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.after[key] = after
+}
+
+// pop returns and removes the requested requeue of the extension
+func (r *requeues) pop(key types.NamespacedName) (time.Duration, bool) {
+	if r == nil {
+		return 0, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	after, ok := r.after[key]
+	delete(r.after, key)
+	return after, ok
+}
+
+// requeueReconciler requeues the extensions after successful reconciliations as requested by the actuator
+type requeueReconciler struct {
+	reconcile.Reconciler
+	requeues *requeues
+}
+
+// Reconcile reconciles the extension and adds the requeue requested by the actuator to the result
+func (r *requeueReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	result, err := r.Reconciler.Reconcile(ctx, request)
+
+	// the request is always removed, it only applies to the reconciliation which made it
+	after, ok := r.requeues.pop(request.NamespacedName)
+	if err != nil || !ok || result.Requeue && (result.RequeueAfter == 0 || result.RequeueAfter < after) {
+		return result, err
+	}
+
+	return reconcile.Result{Requeue: true, RequeueAfter: after}, nil
+}
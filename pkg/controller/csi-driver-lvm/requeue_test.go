@@ -0,0 +1,151 @@
+package csidriverlvm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+)
+
+func TestRequeueReconciler(t *testing.T) {
+	key := types.NamespacedName{Namespace: testNamespace, Name: Type}
+
+	tt := []struct {
+		desc    string
+		result  reconcile.Result
+		err     error
+		request *time.Duration
+		want    reconcile.Result
+		wantErr bool
+	}{
+		{
+			desc:   "test without requeue",
+			result: reconcile.Result{},
+			want:   reconcile.Result{},
+		},
+		{
+			desc:    "test requeue of the actuator",
+			result:  reconcile.Result{},
+			request: ptr.To(2 * time.Hour),
+			want:    reconcile.Result{Requeue: true, RequeueAfter: 2 * time.Hour},
+		},
+		{
+			desc:    "test earlier requeue of the reconciler",
+			result:  reconcile.Result{Requeue: true, RequeueAfter: time.Minute},
+			request: ptr.To(2 * time.Hour),
+			want:    reconcile.Result{Requeue: true, RequeueAfter: time.Minute},
+		},
+		{
+			desc:    "test error of the reconciler",
+			err:     errors.New("failed"),
+			request: ptr.To(2 * time.Hour),
+			want:    reconcile.Result{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			requeues := newRequeues()
+			r := &requeueReconciler{
+				Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					if tc.request != nil {
+						requeues.set(key, *tc.request)
+					}
+					return tc.result, tc.err
+				}),
+				requeues: requeues,
+			}
+
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+
+			// the request of the actuator is consumed by the reconciliation
+			_, ok := requeues.pop(key)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestReconcileRequeuesHeldBackImages(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+	a.requeues = newRequeues()
+	key := types.NamespacedName{Namespace: testNamespace, Name: Type}
+
+	ex := &extensionsv1alpha1.Extension{}
+	require.NoError(t, c.Get(ctx, key, ex))
+	require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+	_, ok := a.requeues.pop(key)
+	assert.False(t, ok)
+
+	// the shoot was deployed with an older driver and its maintenance window opens in two hours
+	require.NoError(t, c.Get(ctx, key, ex))
+	status, err := a.providerStatus(ex)
+	require.NoError(t, err)
+	desired := status.Images[imageCsiDriverLvm]
+	status.Images[imageCsiDriverLvm] = "ghcr.io/metal-stack/csi-driver-lvm:v0.5.2"
+	require.NoError(t, a.updateProviderStatus(ctx, ex, status))
+
+	now := time.Now().UTC()
+	setMaintenanceWindow(t, c, now.Add(2*time.Hour), now.Add(3*time.Hour))
+
+	reconcile := func() string {
+		require.NoError(t, c.Get(ctx, key, ex))
+		require.NoError(t, a.Reconcile(ctx, logr.Discard(), ex))
+		require.NoError(t, c.Get(ctx, key, ex))
+		status, err := a.providerStatus(ex)
+		require.NoError(t, err)
+		return status.Images[imageCsiDriverLvm]
+	}
+
+	assert.Equal(t, "ghcr.io/metal-stack/csi-driver-lvm:v0.5.2", reconcile())
+	requeueAfter, ok := a.requeues.pop(key)
+	require.True(t, ok)
+	assert.InDelta(t, 2*time.Hour, requeueAfter, float64(time.Minute))
+
+	// the forced update is rolled out immediately and the annotation is removed afterwards
+	ex.Annotations = map[string]string{v1alpha1.ForceImageUpdateAnnotation: "true"}
+	require.NoError(t, c.Update(ctx, ex))
+
+	assert.Equal(t, desired, reconcile())
+	assert.NotContains(t, ex.Annotations, v1alpha1.ForceImageUpdateAnnotation)
+	_, ok = a.requeues.pop(key)
+	assert.False(t, ok)
+}
+
+// setMaintenanceWindow sets the maintenance window of the shoot in the Cluster of the test namespace
+func setMaintenanceWindow(t *testing.T, c client.Client, begin, end time.Time) {
+	cluster := &extensionsv1alpha1.Cluster{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: testNamespace}, cluster))
+
+	shoot := &gardencorev1beta1.Shoot{}
+	require.NoError(t, json.Unmarshal(cluster.Spec.Shoot.Raw, shoot))
+	shoot.Spec.Maintenance = &gardencorev1beta1.Maintenance{
+		TimeWindow: &gardencorev1beta1.MaintenanceTimeWindow{Begin: begin.Format("150405-0700"), End: end.Format("150405-0700")},
+	}
+	raw, err := json.Marshal(shoot)
+	require.NoError(t, err)
+
+	cluster.Spec.Shoot = runtime.RawExtension{Raw: raw}
+	require.NoError(t, c.Update(context.Background(), cluster))
+}
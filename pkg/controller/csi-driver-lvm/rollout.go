@@ -0,0 +1,73 @@
+package csidriverlvm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pluginUpdateStrategy returns the update strategy of the plugin daemon sets, it defaults to a rolling update with
+// one unavailable node
+func pluginUpdateStrategy(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) appsv1.DaemonSetUpdateStrategy {
+	maxUnavailable := intstr.FromInt32(1)
+	strategy := appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+
+	configured := csidriverlvmConfig.PluginUpdateStrategy
+	if configured == nil {
+		return strategy
+	}
+
+	if configured.Type != nil && *configured.Type == appsv1.OnDeleteDaemonSetStrategyType {
+		return appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+	}
+	if configured.MaxUnavailable != nil {
+		strategy.RollingUpdate.MaxUnavailable = configured.MaxUnavailable
+	}
+	strategy.RollingUpdate.MaxSurge = configured.MaxSurge
+
+	return strategy
+}
+
+// pluginRollouts returns the rollout progress of the plugin daemon sets in the shoot, daemon sets which have not been
+// created yet are skipped
+func pluginRollouts(ctx context.Context, shootClient client.Client, groups []pluginGroup) ([]v1alpha1.PluginRolloutStatus, error) {
+	rollouts := []v1alpha1.PluginRolloutStatus{}
+	for _, group := range groups {
+		daemonSet := &appsv1.DaemonSet{}
+		if err := shootClient.Get(ctx, client.ObjectKey{Namespace: shootNamespace, Name: group.name}, daemonSet); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get plugin daemon set %q: %w", group.name, err)
+		}
+
+		rollouts = append(rollouts, pluginRollout(daemonSet))
+	}
+
+	return rollouts, nil
+}
+
+// pluginRollout returns the rollout progress of the plugin daemon set, the updated nodes are only counted once the
+// daemon set controller has observed the current generation
+func pluginRollout(daemonSet *appsv1.DaemonSet) v1alpha1.PluginRolloutStatus {
+	rollout := v1alpha1.PluginRolloutStatus{
+		Name:           daemonSet.Name,
+		DesiredNodes:   daemonSet.Status.DesiredNumberScheduled,
+		AvailableNodes: daemonSet.Status.NumberAvailable,
+	}
+	if daemonSet.Status.ObservedGeneration >= daemonSet.Generation {
+		rollout.UpdatedNodes = daemonSet.Status.UpdatedNumberScheduled
+	}
+
+	return rollout
+}
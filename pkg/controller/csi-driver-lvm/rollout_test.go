@@ -0,0 +1,82 @@
+package csidriverlvm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPluginUpdateStrategy(t *testing.T) {
+	tt := []struct {
+		desc     string
+		strategy *v1alpha1.PluginUpdateStrategy
+		want     appsv1.DaemonSetUpdateStrategy
+	}{
+		{
+			desc: "test default strategy",
+			want: appsv1.DaemonSetUpdateStrategy{
+				Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: ptr.To(intstr.FromInt32(1))},
+			},
+		},
+		{
+			desc: "test rolling update",
+			strategy: &v1alpha1.PluginUpdateStrategy{
+				MaxUnavailable: ptr.To(intstr.FromString("25%")),
+				MaxSurge:       ptr.To(intstr.FromInt32(2)),
+			},
+			want: appsv1.DaemonSetUpdateStrategy{
+				Type: appsv1.RollingUpdateDaemonSetStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+					MaxUnavailable: ptr.To(intstr.FromString("25%")),
+					MaxSurge:       ptr.To(intstr.FromInt32(2)),
+				},
+			},
+		},
+		{
+			desc:     "test on delete",
+			strategy: &v1alpha1.PluginUpdateStrategy{Type: ptr.To(appsv1.OnDeleteDaemonSetStrategyType)},
+			want:     appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := pluginUpdateStrategy(&v1alpha1.CsiDriverLvmConfig{PluginUpdateStrategy: tc.strategy})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPluginRollouts(t *testing.T) {
+	shootClient := fake.NewClientBuilder().WithObjects(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "csi-driver-lvm-plugin-a", Generation: 2},
+			Status:     appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 5, UpdatedNumberScheduled: 3, NumberAvailable: 4},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "csi-driver-lvm-plugin-b", Generation: 3},
+			Status:     appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 2},
+		},
+	).Build()
+
+	rollouts, err := pluginRollouts(context.Background(), shootClient, []pluginGroup{
+		{name: "csi-driver-lvm-plugin-a"},
+		{name: "csi-driver-lvm-plugin-b"},
+		{name: "csi-driver-lvm-plugin-c"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []v1alpha1.PluginRolloutStatus{
+		{Name: "csi-driver-lvm-plugin-a", DesiredNodes: 5, UpdatedNodes: 3, AvailableNodes: 4},
+		{Name: "csi-driver-lvm-plugin-b", DesiredNodes: 2, UpdatedNodes: 0, AvailableNodes: 2},
+	}, rollouts)
+}
@@ -0,0 +1,228 @@
+package csidriverlvm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	extensionsconfig "github.com/gardener/gardener/extensions/pkg/apis/config"
+	gutil "github.com/gardener/gardener/extensions/pkg/util"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/gardener/gardener/pkg/utils/secrets"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// shootClientMaxConcurrency is the maximum number of concurrent users of the client of a single shoot
+	shootClientMaxConcurrency = 2
+
+	invalidationReasonRotation     string = "rotation"
+	invalidationReasonUnauthorized string = "unauthorized"
+	invalidationReasonDeletion     string = "deletion"
+	invalidationReasonMigration    string = "migration"
+)
+
+var (
+	shootClientCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "csi_driver_lvm_shoot_client_cache_requests_total",
+		Help: "Number of shoot client requests served by the cache, partitioned by hit or miss.",
+	}, []string{"result"})
+	shootClientCacheInvalidations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "csi_driver_lvm_shoot_client_cache_invalidations_total",
+		Help: "Number of cached shoot clients which were invalidated, partitioned by reason.",
+	}, []string{"reason"})
+	shootClientCacheEntries = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "csi_driver_lvm_shoot_client_cache_entries",
+		Help: "Number of shoot clients in the cache.",
+	})
+	shootClientWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "csi_driver_lvm_shoot_client_wait_seconds",
+		Help: "Time spent waiting for the concurrency limit of a shoot client.",
+	})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(shootClientCacheRequests, shootClientCacheInvalidations, shootClientCacheEntries, shootClientWaitSeconds)
+}
+
+// shootClientCache caches the clients of the shoots by their namespace in the seed. A cached client is replaced as soon
+// as the resource version of the kubeconfig secret of the shoot changes, e.g. on a credential rotation.
+type shootClientCache struct {
+	reader         client.Reader
+	newClient      func(secret *corev1.Secret) (client.Client, *http.Client, error)
+	maxConcurrency int
+
+	lock       sync.Mutex
+	entries    map[string]*shootClientEntry
+	semaphores map[string]chan struct{}
+}
+
+type shootClientEntry struct {
+	resourceVersion string
+	client          client.Client
+	httpClient      *http.Client
+}
+
+// close closes the idle connections of the client, requests still in flight by other reconciliations are not affected
+func (e *shootClientEntry) close() {
+	if e.httpClient != nil {
+		e.httpClient.CloseIdleConnections()
+	}
+}
+
+func newShootClientCache(reader client.Reader) *shootClientCache {
+	return &shootClientCache{
+		reader:         reader,
+		newClient:      newShootClient,
+		maxConcurrency: shootClientMaxConcurrency,
+		entries:        map[string]*shootClientEntry{},
+		semaphores:     map[string]chan struct{}{},
+	}
+}
+
+// Get returns the client of the shoot in the given namespace. The returned release function must be called once the
+// client is no longer used, it frees the slot of the concurrency limit of the shoot.
+func (c *shootClientCache) Get(ctx context.Context, namespace string) (client.Client, func(), error) {
+	secret, err := shootKubeconfigSecret(ctx, c.reader, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shootClient, err := c.client(namespace, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release, err := c.acquire(ctx, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shootClient, release, nil
+}
+
+// Invalidate removes the client of the shoot in the given namespace from the cache, the concurrency limit of the shoot
+// is only dropped together with the client once the shoot is deleted or migrated to another seed
+func (c *shootClientCache) Invalidate(namespace, reason string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if reason == invalidationReasonDeletion || reason == invalidationReasonMigration {
+		delete(c.semaphores, namespace)
+	}
+	entry, ok := c.entries[namespace]
+	if !ok {
+		return
+	}
+
+	entry.close()
+	delete(c.entries, namespace)
+	shootClientCacheInvalidations.WithLabelValues(reason).Inc()
+	shootClientCacheEntries.Set(float64(len(c.entries)))
+}
+
+func (c *shootClientCache) client(namespace string, secret *corev1.Secret) (client.Client, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[namespace]
+	if ok && entry.resourceVersion == secret.ResourceVersion {
+		shootClientCacheRequests.WithLabelValues("hit").Inc()
+		return entry.client, nil
+	}
+	shootClientCacheRequests.WithLabelValues("miss").Inc()
+
+	shootClient, httpClient, err := c.newClient(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shoot client: %w", err)
+	}
+
+	if ok {
+		entry.close()
+		shootClientCacheInvalidations.WithLabelValues(invalidationReasonRotation).Inc()
+	}
+	c.entries[namespace] = &shootClientEntry{resourceVersion: secret.ResourceVersion, client: shootClient, httpClient: httpClient}
+	shootClientCacheEntries.Set(float64(len(c.entries)))
+
+	return shootClient, nil
+}
+
+func (c *shootClientCache) acquire(ctx context.Context, namespace string) (func(), error) {
+	c.lock.Lock()
+	semaphore, ok := c.semaphores[namespace]
+	if !ok {
+		semaphore = make(chan struct{}, c.maxConcurrency)
+		c.semaphores[namespace] = semaphore
+	}
+	c.lock.Unlock()
+
+	start := time.Now()
+	select {
+	case semaphore <- struct{}{}:
+		shootClientWaitSeconds.Observe(time.Since(start).Seconds())
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to wait for shoot client: %w", ctx.Err())
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-semaphore })
+	}, nil
+}
+
+// shootKubeconfigSecret returns the kubeconfig secret of the shoot the same way as gutil.NewClientForShoot
+func shootKubeconfigSecret(ctx context.Context, reader client.Reader, namespace string) (*corev1.Secret, error) {
+	var (
+		secret = &corev1.Secret{}
+		err    error
+	)
+
+	if os.Getenv("GARDENER_SHOOT_CLIENT") != "external" {
+		if err = reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: v1beta1constants.SecretNameGardenerInternal}, secret); err != nil && apierrors.IsNotFound(err) {
+			err = reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: v1beta1constants.SecretNameGardener}, secret)
+		}
+	} else {
+		err = reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: v1beta1constants.SecretNameGardener}, secret)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get shoot kubeconfig secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// newShootClient returns a client for the shoot together with its HTTP client, the connections of the HTTP client are
+// closed once the client is replaced or removed from the cache
+func newShootClient(secret *corev1.Secret) (client.Client, *http.Client, error) {
+	restConfig, err := gutil.NewRESTConfigFromKubeconfig(secret.Data[secrets.DataKeyKubeconfig])
+	if err != nil {
+		return nil, nil, err
+	}
+	gutil.ApplyRESTOptions(restConfig, extensionsconfig.RESTOptions{})
+
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get HTTP client for config: %w", err)
+	}
+
+	mapper, err := apiutil.NewDynamicRESTMapper(restConfig, httpClient)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create new DynamicRESTMapper: %w", err)
+	}
+
+	shootClient, err := client.New(restConfig, client.Options{HTTPClient: httpClient, Mapper: mapper})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return shootClient, httpClient, nil
+}
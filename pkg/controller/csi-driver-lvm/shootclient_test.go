@@ -0,0 +1,130 @@
+package csidriverlvm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestShootClientCache(t *testing.T) {
+	ctx := context.Background()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: v1beta1constants.SecretNameGardenerInternal},
+		Data:       map[string][]byte{"kubeconfig": []byte("old")},
+	}
+	c := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	created := 0
+	transports := []*closeRecordingTransport{}
+	cache := newShootClientCache(c)
+	cache.newClient = func(secret *corev1.Secret) (client.Client, *http.Client, error) {
+		created++
+		transport := &closeRecordingTransport{}
+		transports = append(transports, transport)
+		return fake.NewClientBuilder().Build(), &http.Client{Transport: transport}, nil
+	}
+
+	first, release, err := cache.Get(ctx, testNamespace)
+	require.NoError(t, err)
+	release()
+
+	cached, release, err := cache.Get(ctx, testNamespace)
+	require.NoError(t, err)
+	release()
+	assert.Same(t, first, cached)
+	assert.Equal(t, 1, created)
+
+	// the credential rotation updates the kubeconfig secret
+	secret.Data["kubeconfig"] = []byte("new")
+	require.NoError(t, c.Update(ctx, secret))
+
+	rotated, release, err := cache.Get(ctx, testNamespace)
+	require.NoError(t, err)
+	release()
+	assert.NotSame(t, first, rotated)
+	assert.Equal(t, 2, created)
+	// the connections of the replaced client must not be leaked
+	assert.Equal(t, 1, transports[0].closed)
+	assert.Equal(t, 0, transports[1].closed)
+
+	cache.Invalidate(testNamespace, invalidationReasonUnauthorized)
+	assert.Equal(t, 1, transports[1].closed)
+
+	_, release, err = cache.Get(ctx, testNamespace)
+	require.NoError(t, err)
+	release()
+	assert.Equal(t, 3, created)
+
+	_, _, err = cache.Get(ctx, "shoot--test--missing")
+	assert.Error(t, err)
+}
+
+func TestShootClientCacheConcurrency(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: v1beta1constants.SecretNameGardener},
+	}
+
+	cache := newShootClientCache(fake.NewClientBuilder().WithObjects(secret).Build())
+	cache.newClient = func(secret *corev1.Secret) (client.Client, *http.Client, error) {
+		return fake.NewClientBuilder().Build(), nil, nil
+	}
+
+	releases := []func(){}
+	for range shootClientMaxConcurrency {
+		_, release, err := cache.Get(context.Background(), testNamespace)
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := cache.Get(ctx, testNamespace)
+	require.Error(t, err)
+
+	// releasing twice must not free a slot of another user
+	releases[0]()
+	releases[0]()
+
+	_, release, err := cache.Get(context.Background(), testNamespace)
+	require.NoError(t, err)
+	release()
+}
+
+func TestMigrateInvalidatesShootClient(t *testing.T) {
+	ctx := context.Background()
+	a, c := newTestActuator(t, testNamespace)
+
+	_, release, err := a.shootClients.Get(ctx, testNamespace)
+	require.NoError(t, err)
+	release()
+	require.Contains(t, a.shootClients.entries, testNamespace)
+
+	ex := &extensionsv1alpha1.Extension{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: Type}, ex))
+	require.NoError(t, a.Migrate(ctx, logr.Discard(), ex))
+
+	// the shoot is reconciled by another seed, neither its client nor its concurrency limit are kept
+	assert.NotContains(t, a.shootClients.entries, testNamespace)
+	assert.NotContains(t, a.shootClients.semaphores, testNamespace)
+}
+
+// closeRecordingTransport counts how often the idle connections of the HTTP client were closed
+type closeRecordingTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *closeRecordingTransport) CloseIdleConnections() {
+	t.closed++
+}
@@ -0,0 +1,40 @@
+package csidriverlvm
+
+import (
+	"context"
+	"fmt"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// providerStatus decodes the provider status of the extension, an empty status is returned if none has been written yet
+func (a *actuator) providerStatus(ex *extensionsv1alpha1.Extension) (*v1alpha1.CsiDriverLvmStatus, error) {
+	status := &v1alpha1.CsiDriverLvmStatus{}
+	if ex.Status.ProviderStatus == nil || ex.Status.ProviderStatus.Raw == nil {
+		return status, nil
+	}
+
+	_, _, err := a.decoder.Decode(ex.Status.ProviderStatus.Raw, nil, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode provider status: %w", err)
+	}
+
+	return status, nil
+}
+
+// updateProviderStatus writes the provider status of the extension
+func (a *actuator) updateProviderStatus(ctx context.Context, ex *extensionsv1alpha1.Extension, status *v1alpha1.CsiDriverLvmStatus) error {
+	status.TypeMeta = metav1.TypeMeta{
+		APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		Kind:       "CsiDriverLvmStatus",
+	}
+
+	patch := client.MergeFrom(ex.DeepCopy())
+	ex.Status.ProviderStatus = &runtime.RawExtension{Object: status}
+	return a.client.Status().Patch(ctx, ex, patch)
+}
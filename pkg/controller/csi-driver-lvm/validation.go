@@ -0,0 +1,207 @@
+package csidriverlvm
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"k8s.io/utils/ptr"
+)
+
+// validateConfig checks the shoot configuration against the default storage classes, the restrictions configured by the operator
+// and the worker pools of the cluster
+func validateConfig(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, cluster *extensionscontroller.Cluster) error {
+	var errs []error
+
+	for _, sc := range csidriverlvmConfig.StorageClasses {
+		if isDefaultStorageClass(sc.Name) {
+			for _, defaultStorageClass := range defaultStorageClasses {
+				if defaultStorageClass.name == sc.Name && sc.Type != nil && *sc.Type != defaultStorageClass.volumeType {
+					errs = append(errs, fmt.Errorf("type of default storage class %q cannot be changed to %q", sc.Name, *sc.Type))
+				}
+			}
+		} else if sc.Type == nil {
+			errs = append(errs, fmt.Errorf("storage class %q requires a type", sc.Name))
+		}
+
+		if sc.FsType != nil && !slices.Contains(controllerConfig.AllowedFsTypes, *sc.FsType) {
+			errs = append(errs, fmt.Errorf("fsType %q of storage class %q is not allowed", *sc.FsType, sc.Name))
+		}
+		for _, option := range sc.MountOptions {
+			if !slices.Contains(controllerConfig.AllowedMountOptions, option) {
+				errs = append(errs, fmt.Errorf("mount option %q of storage class %q is not allowed", option, sc.Name))
+			}
+		}
+	}
+
+	for _, sc := range mergedStorageClasses(csidriverlvmConfig) {
+		if err := validateVolumeType(sc, ptr.Deref(controllerConfig.AllowThinAndRaid, false)); err != nil {
+			errs = append(errs, err)
+		}
+		errs = append(errs, validateGeometry(sc, cluster, ptr.Deref(controllerConfig.AllowGeometry, false))...)
+		errs = append(errs, validateEncryption(sc, cluster, ptr.Deref(controllerConfig.AllowEncryption, false))...)
+	}
+
+	if name := csidriverlvmConfig.DefaultStorageClass; name != nil && !slices.ContainsFunc(mergedStorageClasses(csidriverlvmConfig), func(sc v1alpha1.StorageClass) bool {
+		return sc.Name == *name
+	}) {
+		errs = append(errs, fmt.Errorf("default storage class %q does not exist", *name))
+	}
+
+	// the fields changed by a patch are checked when the patch is applied to the rendered object
+	for _, patch := range csidriverlvmConfig.Patches {
+		if len(allowedPatchPaths(controllerConfig.AllowedPatches, patch.Kind)) == 0 {
+			errs = append(errs, fmt.Errorf("patches of kind %q are not allowed", patch.Kind))
+		}
+	}
+
+	if csidriverlvmConfig.LoopDevices != nil && !isDevelopmentShoot(cluster) {
+		errs = append(errs, errors.New("loop devices are only supported for shoots with purpose development"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateWorkerPools checks the node specific settings resolved for the worker pools
+func validateWorkerPools(workerPools []v1alpha1.WorkerPoolStatus) error {
+	var errs []error
+
+	for _, pool := range workerPools {
+		if !filepath.IsAbs(pool.HostWritePath) {
+			errs = append(errs, fmt.Errorf("hostWritePath %q of worker pool %q is not absolute", pool.HostWritePath, pool.Name))
+		}
+		if !filepath.IsAbs(pool.KubeletRootDir) {
+			errs = append(errs, fmt.Errorf("kubelet root directory %q of worker pool %q is not absolute", pool.KubeletRootDir, pool.Name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isDevelopmentShoot returns true if the purpose of the shoot is development
+func isDevelopmentShoot(cluster *extensionscontroller.Cluster) bool {
+	return cluster != nil && cluster.Shoot != nil && ptr.Deref(cluster.Shoot.Spec.Purpose, "") == gardencorev1beta1.ShootPurposeDevelopment
+}
+
+// thinAndRaidVolumeTypes contains the volume types beyond linear, mirror and striped, csi-driver-lvm v0.6.0 does not
+// support them and the operator has to confirm that the deployed image does
+var thinAndRaidVolumeTypes = []string{v1alpha1.VolumeTypeThin, v1alpha1.VolumeTypeRaid1, v1alpha1.VolumeTypeRaid5, v1alpha1.VolumeTypeRaid10}
+
+// validateVolumeType checks that thin and raid volume types are allowed by the operator
+func validateVolumeType(sc v1alpha1.StorageClass, allowThinAndRaid bool) error {
+	volumeType := pointer.SafeDeref(sc.Type)
+
+	if slices.Contains(thinAndRaidVolumeTypes, volumeType) && !allowThinAndRaid {
+		return fmt.Errorf("type %q of storage class %q is not allowed", volumeType, sc.Name)
+	}
+
+	return nil
+}
+
+// validateGeometry checks that the geometry parameters are allowed by the operator, match the volume type and fit the
+// number of devices of every worker pool where this number is known
+func validateGeometry(sc v1alpha1.StorageClass, cluster *extensionscontroller.Cluster, allowed bool) []error {
+	var (
+		errs       []error
+		volumeType = pointer.SafeDeref(sc.Type)
+	)
+
+	// csi-driver-lvm v0.6.0 ignores the geometry parameters, it stripes volumes over all devices and creates mirrors
+	// with a single copy, the operator has to confirm that the deployed image reads them
+	if (sc.Stripes != nil || sc.StripeSize != nil || sc.Mirrors != nil) && !allowed {
+		errs = append(errs, fmt.Errorf("stripes, stripeSize and mirrors of storage class %q are not allowed", sc.Name))
+	}
+
+	if (sc.Stripes != nil || sc.StripeSize != nil) && !slices.Contains([]string{v1alpha1.VolumeTypeStriped, v1alpha1.VolumeTypeRaid5, v1alpha1.VolumeTypeRaid10}, volumeType) {
+		errs = append(errs, fmt.Errorf("stripes and stripeSize of storage class %q are not supported for type %q", sc.Name, volumeType))
+	}
+	if sc.Mirrors != nil && !slices.Contains([]string{v1alpha1.VolumeTypeMirror, v1alpha1.VolumeTypeRaid1, v1alpha1.VolumeTypeRaid10}, volumeType) {
+		errs = append(errs, fmt.Errorf("mirrors of storage class %q are not supported for type %q", sc.Name, volumeType))
+	}
+	if sc.ThinPool != nil && volumeType != v1alpha1.VolumeTypeThin {
+		errs = append(errs, fmt.Errorf("thinPool of storage class %q is only supported for type %q", sc.Name, v1alpha1.VolumeTypeThin))
+	}
+
+	devices := requiredDevices(sc)
+	if devices == 0 || cluster == nil || cluster.Shoot == nil {
+		return errs
+	}
+
+	for _, worker := range cluster.Shoot.Spec.Provider.Workers {
+		// the devices of a pool are only known if they are configured as data volumes
+		if len(worker.DataVolumes) == 0 {
+			continue
+		}
+		if int32(len(worker.DataVolumes)) < devices {
+			errs = append(errs, fmt.Errorf("storage class %q requires %d devices but worker pool %q only has %d", sc.Name, devices, worker.Name, len(worker.DataVolumes)))
+		}
+	}
+
+	return errs
+}
+
+// requiredDevices returns the minimum number of devices needed for volumes of the storage class,
+// zero if the driver defaults are used for a storage class without raid level
+func requiredDevices(sc v1alpha1.StorageClass) int32 {
+	var (
+		stripes = ptr.Deref(sc.Stripes, 2)
+		mirrors = ptr.Deref(sc.Mirrors, 1)
+	)
+
+	switch pointer.SafeDeref(sc.Type) {
+	case v1alpha1.VolumeTypeStriped:
+		return ptr.Deref(sc.Stripes, 0)
+	case v1alpha1.VolumeTypeMirror:
+		if sc.Mirrors == nil {
+			return 0
+		}
+		return mirrors + 1
+	case v1alpha1.VolumeTypeRaid1:
+		return mirrors + 1
+	case v1alpha1.VolumeTypeRaid5:
+		return stripes + 1
+	case v1alpha1.VolumeTypeRaid10:
+		return stripes * (mirrors + 1)
+	default:
+		return 0
+	}
+}
+
+// validateEncryption checks that encryption is allowed by the operator, that it is only used with volume types
+// supporting it and that the key is referenced by the shoot
+func validateEncryption(sc v1alpha1.StorageClass, cluster *extensionscontroller.Cluster, allowed bool) []error {
+	var errs []error
+
+	if sc.Encryption == nil {
+		return nil
+	}
+
+	// csi-driver-lvm images without LUKS support ignore the encryption parameter and create plaintext volumes, the
+	// operator has to confirm that the deployed image supports it
+	if !allowed {
+		errs = append(errs, fmt.Errorf("encryption of storage class %q is not allowed", sc.Name))
+	}
+
+	// dm-crypt does not pass discards by default, deleted blocks would never be returned to the thin pool
+	if pointer.SafeDeref(sc.Type) == v1alpha1.VolumeTypeThin {
+		errs = append(errs, fmt.Errorf("encryption of storage class %q is not supported for type %q", sc.Name, v1alpha1.VolumeTypeThin))
+	}
+
+	if cluster != nil && cluster.Shoot != nil {
+		resource := v1beta1helper.GetResourceByName(cluster.Shoot.Spec.Resources, sc.Encryption.SecretResourceName)
+		if resource == nil {
+			errs = append(errs, fmt.Errorf("encryption of storage class %q references resource %q which is not part of the shoot resources", sc.Name, sc.Encryption.SecretResourceName))
+		} else if resource.ResourceRef.Kind != "Secret" {
+			errs = append(errs, fmt.Errorf("encryption of storage class %q references resource %q which is not a secret", sc.Name, sc.Encryption.SecretResourceName))
+		}
+	}
+
+	return errs
+}
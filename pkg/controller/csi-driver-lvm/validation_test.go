@@ -0,0 +1,287 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+)
+
+func TestValidateConfig(t *testing.T) {
+	controllerConfig := config.ControllerConfiguration{
+		AllowedFsTypes:      []string{"ext4", "xfs"},
+		AllowedMountOptions: []string{"noatime", "discard"},
+		AllowedPatches:      []config.AllowedPatch{{Kind: "DaemonSet", Paths: []string{"/metadata/annotations"}}},
+	}
+
+	cluster := &extensionscontroller.Cluster{
+		Shoot: &gardencorev1beta1.Shoot{
+			Spec: gardencorev1beta1.ShootSpec{
+				Resources: []gardencorev1beta1.NamedResourceReference{
+					{Name: "luks-key", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "Secret", Name: "luks-key", APIVersion: "v1"}},
+					{Name: "luks-config", ResourceRef: autoscalingv1.CrossVersionObjectReference{Kind: "ConfigMap", Name: "luks-config", APIVersion: "v1"}},
+				},
+				Provider: gardencorev1beta1.Provider{
+					Workers: []gardencorev1beta1.Worker{
+						{Name: "unknown-devices"},
+						{Name: "four-devices", DataVolumes: make([]gardencorev1beta1.DataVolume, 4)},
+					},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		desc           string
+		storageClasses []v1alpha1.StorageClass
+		allowThinRaid  bool
+		allowGeometry  bool
+		allowEncrypt   bool
+		loopDevices    *v1alpha1.LoopDevices
+		purpose        *gardencorev1beta1.ShootPurpose
+		defaultClass   *string
+		patches        []v1alpha1.Patch
+		valid          bool
+	}{
+		{
+			desc:  "test without storage classes",
+			valid: true,
+		},
+		{
+			desc: "test allowed fsType and mount options",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", FsType: ptr.To("xfs"), MountOptions: []string{"noatime", "discard"}},
+			},
+			valid: true,
+		},
+		{
+			desc: "test additional storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-mirror-xfs", Type: ptr.To("mirror"), FsType: ptr.To("xfs")},
+			},
+			valid: true,
+		},
+		{
+			desc: "test additional storage class without type",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-mirror-xfs", FsType: ptr.To("xfs")},
+			},
+			valid: false,
+		},
+		{
+			desc: "test changed type of default storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", Type: ptr.To("mirror")},
+			},
+			valid: false,
+		},
+		{
+			desc: "test fsType not allowed",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", FsType: ptr.To("btrfs")},
+			},
+			valid: false,
+		},
+		{
+			desc: "test mount option not allowed",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", MountOptions: []string{"noatime", "nobarrier"}},
+			},
+			valid: false,
+		},
+		{
+			desc: "test stripes fitting the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-striped", Stripes: ptr.To(int32(4)), StripeSize: ptr.To(resource.MustParse("64Ki"))},
+			},
+			allowGeometry: true,
+			valid:         true,
+		},
+		{
+			desc: "test stripes exceeding the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-striped", Stripes: ptr.To(int32(5))},
+			},
+			allowGeometry: true,
+			valid:         false,
+		},
+		{
+			desc: "test mirrors fitting the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-mirror", Mirrors: ptr.To(int32(3))},
+			},
+			allowGeometry: true,
+			valid:         true,
+		},
+		{
+			desc: "test mirrors exceeding the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-mirror", Mirrors: ptr.To(int32(4))},
+			},
+			allowGeometry: true,
+			valid:         false,
+		},
+		{
+			desc: "test geometry not allowed",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-striped", Stripes: ptr.To(int32(4))},
+			},
+			valid: false,
+		},
+		{
+			desc: "test stripes on mirror storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-mirror", Stripes: ptr.To(int32(2))},
+			},
+			allowGeometry: true,
+			valid:         false,
+		},
+		{
+			desc: "test mirrors on additional linear storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear-mirrored", Type: ptr.To("linear"), Mirrors: ptr.To(int32(1))},
+			},
+			allowGeometry: true,
+			valid:         false,
+		},
+		{
+			desc: "test thin storage class not allowed",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-thin", Type: ptr.To("thin")},
+			},
+			valid: false,
+		},
+		{
+			desc: "test thin storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-thin", Type: ptr.To("thin"), ThinPool: &v1alpha1.ThinPool{SizePercentage: ptr.To(int32(80)), OvercommitRatio: ptr.To(int32(2))}},
+			},
+			allowThinRaid: true,
+			valid:         true,
+		},
+		{
+			desc: "test thin pool on linear storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", ThinPool: &v1alpha1.ThinPool{SizePercentage: ptr.To(int32(80))}},
+			},
+			valid: false,
+		},
+		{
+			desc: "test raid5 storage class fitting the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-raid5", Type: ptr.To("raid5"), Stripes: ptr.To(int32(3))},
+			},
+			allowThinRaid: true,
+			allowGeometry: true,
+			valid:         true,
+		},
+		{
+			desc: "test raid10 storage class exceeding the devices",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-raid10", Type: ptr.To("raid10"), Stripes: ptr.To(int32(3))},
+			},
+			allowThinRaid: true,
+			allowGeometry: true,
+			valid:         false,
+		},
+		{
+			desc: "test encrypted storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", Encryption: &v1alpha1.Encryption{SecretResourceName: "luks-key"}},
+			},
+			allowEncrypt: true,
+			valid:        true,
+		},
+		{
+			desc: "test encrypted storage class not allowed",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", Encryption: &v1alpha1.Encryption{SecretResourceName: "luks-key"}},
+			},
+			valid: false,
+		},
+		{
+			desc: "test encrypted storage class with unknown resource",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", Encryption: &v1alpha1.Encryption{SecretResourceName: "unknown"}},
+			},
+			allowEncrypt: true,
+			valid:        false,
+		},
+		{
+			desc: "test encrypted storage class referencing a config map",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-linear", Encryption: &v1alpha1.Encryption{SecretResourceName: "luks-config"}},
+			},
+			allowEncrypt: true,
+			valid:        false,
+		},
+		{
+			desc: "test encrypted thin storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-thin", Type: ptr.To("thin"), Encryption: &v1alpha1.Encryption{SecretResourceName: "luks-key"}},
+			},
+			allowThinRaid: true,
+			allowEncrypt:  true,
+			valid:         false,
+		},
+		{
+			desc:        "test loop devices in development shoot",
+			loopDevices: &v1alpha1.LoopDevices{Count: 2, Size: resource.MustParse("10Gi")},
+			purpose:     ptr.To(gardencorev1beta1.ShootPurposeDevelopment),
+			valid:       true,
+		},
+		{
+			desc:        "test loop devices in production shoot",
+			loopDevices: &v1alpha1.LoopDevices{Count: 2, Size: resource.MustParse("10Gi")},
+			purpose:     ptr.To(gardencorev1beta1.ShootPurposeProduction),
+			valid:       false,
+		},
+		{
+			desc:         "test default storage class",
+			defaultClass: ptr.To("csi-driver-lvm-mirror"),
+			valid:        true,
+		},
+		{
+			desc: "test additional default storage class",
+			storageClasses: []v1alpha1.StorageClass{
+				{Name: "csi-driver-lvm-striped-xfs", Type: ptr.To("striped"), FsType: ptr.To("xfs")},
+			},
+			defaultClass: ptr.To("csi-driver-lvm-striped-xfs"),
+			valid:        true,
+		},
+		{
+			desc:         "test unknown default storage class",
+			defaultClass: ptr.To("unknown"),
+			valid:        false,
+		},
+		{
+			desc:    "test patch of allowed kind",
+			patches: []v1alpha1.Patch{{Kind: "DaemonSet", Name: "csi-driver-lvm-plugin", Patch: "metadata: {}"}},
+			valid:   true,
+		},
+		{
+			desc:    "test patch of kind which is not allowed",
+			patches: []v1alpha1.Patch{{Kind: "StorageClass", Name: "csi-lvm", Patch: "metadata: {}"}},
+			valid:   false,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			controllerConfig := *controllerConfig.DeepCopy()
+			controllerConfig.AllowThinAndRaid = ptr.To(tc.allowThinRaid)
+			controllerConfig.AllowGeometry = ptr.To(tc.allowGeometry)
+			controllerConfig.AllowEncryption = ptr.To(tc.allowEncrypt)
+			shoot := cluster.Shoot.DeepCopy()
+			shoot.Spec.Purpose = tc.purpose
+			err := validateConfig(&v1alpha1.CsiDriverLvmConfig{StorageClasses: tc.storageClasses, LoopDevices: tc.loopDevices, DefaultStorageClass: tc.defaultClass, Patches: tc.patches}, controllerConfig, &extensionscontroller.Cluster{Shoot: shoot})
+			assert.Equal(t, tc.valid, err == nil, "error: %v", err)
+		})
+	}
+}
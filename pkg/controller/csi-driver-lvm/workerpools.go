@@ -0,0 +1,214 @@
+package csidriverlvm
+
+import (
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	pluginName            string = "csi-driver-lvm-plugin"
+	defaultKubeletRootDir string = "/var/lib/kubelet"
+	defaultDevDir         string = "/dev"
+	defaultModulesDir     string = "/lib/modules"
+)
+
+// WorkerPools resolves the node specific settings of the worker pools of the cluster, it must be called before the
+// configuration is defaulted. A host write path configured in the shoot takes precedence over the machine image
+// defaults of the operator, which take precedence over the default host write path. The kubelet root directory is
+// resolved in the same order from the paths of the shoot, the machine image defaults and the default paths.
+func WorkerPools(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, cluster *extensionscontroller.Cluster) []v1alpha1.WorkerPoolStatus {
+	if cluster == nil || cluster.Shoot == nil {
+		return nil
+	}
+
+	pools := []v1alpha1.WorkerPoolStatus{}
+	for _, worker := range cluster.Shoot.Spec.Provider.Workers {
+		pool := v1alpha1.WorkerPoolStatus{
+			Name:           worker.Name,
+			HostWritePath:  ptr.Deref(controllerConfig.DefaultHostWritePath, ""),
+			KubeletRootDir: defaultKubeletRootDir,
+		}
+		if controllerConfig.DefaultPaths != nil {
+			pool.KubeletRootDir = ptr.Deref(controllerConfig.DefaultPaths.KubeletRootDir, pool.KubeletRootDir)
+		}
+
+		if worker.Machine.Image != nil {
+			pool.MachineImage = worker.Machine.Image.Name
+		}
+		for _, defaults := range controllerConfig.MachineImageDefaults {
+			if defaults.Name != pool.MachineImage {
+				continue
+			}
+			pool.HostWritePath = ptr.Deref(defaults.HostWritePath, pool.HostWritePath)
+			pool.KubeletRootDir = ptr.Deref(defaults.KubeletRootDir, pool.KubeletRootDir)
+		}
+
+		pool.HostWritePath = ptr.Deref(csidriverlvmConfig.HostWritePath, pool.HostWritePath)
+		if csidriverlvmConfig.Paths != nil {
+			pool.KubeletRootDir = ptr.Deref(csidriverlvmConfig.Paths.KubeletRootDir, pool.KubeletRootDir)
+		}
+
+		pools = append(pools, pool)
+	}
+
+	return pools
+}
+
+// WorkerPool returns the resolved settings of the worker pool with the given name
+func WorkerPool(workerPools []v1alpha1.WorkerPoolStatus, name string) *v1alpha1.WorkerPoolStatus {
+	for i := range workerPools {
+		if workerPools[i].Name == name {
+			return &workerPools[i]
+		}
+	}
+	return nil
+}
+
+// nodePaths are the host paths used by the driver on the nodes, all mounts of the plugin and the controller are derived from them
+type nodePaths struct {
+	kubeletRootDir  string
+	pluginDir       string
+	registrationDir string
+	devDir          string
+	modulesDir      string
+}
+
+// resolvePaths resolves the host paths for the given kubelet root directory. Paths configured in the shoot take
+// precedence over the default paths of the operator, the plugin and registration directories default to the
+// directories below the kubelet root directory.
+func resolvePaths(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, kubeletRootDir string) nodePaths {
+	paths := nodePaths{
+		kubeletRootDir:  kubeletRootDir,
+		pluginDir:       kubeletRootDir + "/plugins/csi-driver-lvm",
+		registrationDir: kubeletRootDir + "/plugins_registry",
+		devDir:          defaultDevDir,
+		modulesDir:      defaultModulesDir,
+	}
+
+	if defaults := controllerConfig.DefaultPaths; defaults != nil {
+		paths.pluginDir = ptr.Deref(defaults.PluginDir, paths.pluginDir)
+		paths.registrationDir = ptr.Deref(defaults.RegistrationDir, paths.registrationDir)
+		paths.devDir = ptr.Deref(defaults.DevDir, paths.devDir)
+		paths.modulesDir = ptr.Deref(defaults.ModulesDir, paths.modulesDir)
+	}
+	if configured := csidriverlvmConfig.Paths; configured != nil {
+		paths.pluginDir = ptr.Deref(configured.PluginDir, paths.pluginDir)
+		paths.registrationDir = ptr.Deref(configured.RegistrationDir, paths.registrationDir)
+		paths.devDir = ptr.Deref(configured.DevDir, paths.devDir)
+		paths.modulesDir = ptr.Deref(configured.ModulesDir, paths.modulesDir)
+	}
+
+	return paths
+}
+
+// pluginGroup contains the worker pools sharing the same node specific settings, they are served by one plugin daemon set
+type pluginGroup struct {
+	name          string
+	pools         []string
+	hostWritePath string
+	paths         nodePaths
+}
+
+// nodeAffinity returns the node affinity restricting pods to the worker pools of the group, it is nil if the group
+// serves all nodes
+func (group pluginGroup) nodeAffinity() *corev1.NodeAffinity {
+	if len(group.pools) == 0 {
+		return nil
+	}
+
+	return &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{
+				{
+					MatchExpressions: []corev1.NodeSelectorRequirement{
+						{
+							Key:      v1beta1constants.LabelWorkerPool,
+							Operator: corev1.NodeSelectorOpIn,
+							Values:   group.pools,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// podAffinity returns the pod affinity co-locating pods with a plugin pod of the group, the controller uses the socket
+// of the plugin through a host path and would stall on nodes without it
+func (group pluginGroup) podAffinity() *corev1.PodAffinity {
+	return &corev1.PodAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+			{
+				LabelSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{
+							Key:      "app",
+							Operator: metav1.LabelSelectorOpIn,
+							Values:   []string{group.name},
+						},
+					},
+				},
+				TopologyKey: corev1.LabelHostname,
+			},
+		},
+	}
+}
+
+// pluginGroups groups the worker pools by their node specific settings. The plugin daemon set is only split if the
+// worker pools differ, otherwise a single daemon set serves all nodes. The group with the default settings keeps the
+// name of the single daemon set, so that its daemon set is not replaced when a worker pool with other settings is added.
+func pluginGroups(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, controllerConfig config.ControllerConfiguration, workerPools []v1alpha1.WorkerPoolStatus) []pluginGroup {
+	kubeletRootDir := defaultKubeletRootDir
+	if controllerConfig.DefaultPaths != nil {
+		kubeletRootDir = ptr.Deref(controllerConfig.DefaultPaths.KubeletRootDir, kubeletRootDir)
+	}
+	if csidriverlvmConfig.Paths != nil {
+		kubeletRootDir = ptr.Deref(csidriverlvmConfig.Paths.KubeletRootDir, kubeletRootDir)
+	}
+	defaultGroup := pluginGroup{
+		name:          pluginName,
+		hostWritePath: ptr.Deref(csidriverlvmConfig.HostWritePath, ""),
+		paths:         resolvePaths(csidriverlvmConfig, controllerConfig, kubeletRootDir),
+	}
+
+	groups := []pluginGroup{}
+	for _, pool := range workerPools {
+		paths := resolvePaths(csidriverlvmConfig, controllerConfig, pool.KubeletRootDir)
+
+		found := false
+		for i := range groups {
+			if groups[i].hostWritePath == pool.HostWritePath && groups[i].paths == paths {
+				groups[i].pools = append(groups[i].pools, pool.Name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			name := pluginName + "-" + pool.Name
+			if pool.HostWritePath == defaultGroup.hostWritePath && paths == defaultGroup.paths {
+				name = pluginName
+			}
+			groups = append(groups, pluginGroup{
+				name:          name,
+				pools:         []string{pool.Name},
+				hostWritePath: pool.HostWritePath,
+				paths:         paths,
+			})
+		}
+	}
+
+	switch len(groups) {
+	case 0:
+		return []pluginGroup{defaultGroup}
+	case 1:
+		groups[0].name = pluginName
+		groups[0].pools = nil
+	}
+
+	return groups
+}
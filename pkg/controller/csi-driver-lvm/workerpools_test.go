@@ -0,0 +1,210 @@
+package csidriverlvm
+
+import (
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/utils/ptr"
+)
+
+func TestWorkerPools(t *testing.T) {
+	controllerConfig := config.ControllerConfiguration{
+		DefaultHostWritePath: ptr.To("/etc/lvm"),
+		MachineImageDefaults: []config.MachineImageDefaults{
+			{Name: "talos", HostWritePath: ptr.To("/var/etc/lvm"), KubeletRootDir: ptr.To("/var/lib/kubelet-talos")},
+			{Name: "flatcar", KubeletRootDir: ptr.To("/var/lib/kubelet-flatcar")},
+		},
+	}
+
+	cluster := &extensionscontroller.Cluster{
+		Shoot: &gardencorev1beta1.Shoot{
+			Spec: gardencorev1beta1.ShootSpec{
+				Provider: gardencorev1beta1.Provider{
+					Workers: []gardencorev1beta1.Worker{
+						{Name: "default", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "gardenlinux"}}},
+						{Name: "talos", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "talos"}}},
+						{Name: "flatcar", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "flatcar"}}},
+						{Name: "no-image"},
+					},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		desc          string
+		hostWritePath *string
+		paths         *v1alpha1.Paths
+		cluster       *extensionscontroller.Cluster
+		want          []v1alpha1.WorkerPoolStatus
+	}{
+		{
+			desc:    "test machine image defaults",
+			cluster: cluster,
+			want: []v1alpha1.WorkerPoolStatus{
+				{Name: "default", MachineImage: "gardenlinux", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "talos", MachineImage: "talos", HostWritePath: "/var/etc/lvm", KubeletRootDir: "/var/lib/kubelet-talos"},
+				{Name: "flatcar", MachineImage: "flatcar", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet-flatcar"},
+				{Name: "no-image", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+			},
+		},
+		{
+			desc:          "test explicit host write path",
+			hostWritePath: ptr.To("/opt/lvm"),
+			cluster:       cluster,
+			want: []v1alpha1.WorkerPoolStatus{
+				{Name: "default", MachineImage: "gardenlinux", HostWritePath: "/opt/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "talos", MachineImage: "talos", HostWritePath: "/opt/lvm", KubeletRootDir: "/var/lib/kubelet-talos"},
+				{Name: "flatcar", MachineImage: "flatcar", HostWritePath: "/opt/lvm", KubeletRootDir: "/var/lib/kubelet-flatcar"},
+				{Name: "no-image", HostWritePath: "/opt/lvm", KubeletRootDir: "/var/lib/kubelet"},
+			},
+		},
+		{
+			desc:    "test explicit kubelet root dir",
+			paths:   &v1alpha1.Paths{KubeletRootDir: ptr.To("/data/kubelet")},
+			cluster: cluster,
+			want: []v1alpha1.WorkerPoolStatus{
+				{Name: "default", MachineImage: "gardenlinux", HostWritePath: "/etc/lvm", KubeletRootDir: "/data/kubelet"},
+				{Name: "talos", MachineImage: "talos", HostWritePath: "/var/etc/lvm", KubeletRootDir: "/data/kubelet"},
+				{Name: "flatcar", MachineImage: "flatcar", HostWritePath: "/etc/lvm", KubeletRootDir: "/data/kubelet"},
+				{Name: "no-image", HostWritePath: "/etc/lvm", KubeletRootDir: "/data/kubelet"},
+			},
+		},
+		{
+			desc: "test without cluster",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := WorkerPools(&v1alpha1.CsiDriverLvmConfig{HostWritePath: tc.hostWritePath, Paths: tc.paths}, controllerConfig, tc.cluster)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPluginGroups(t *testing.T) {
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{HostWritePath: ptr.To("/etc/lvm")}
+	defaultPaths := nodePaths{
+		kubeletRootDir:  "/var/lib/kubelet",
+		pluginDir:       "/var/lib/kubelet/plugins/csi-driver-lvm",
+		registrationDir: "/var/lib/kubelet/plugins_registry",
+		devDir:          "/dev",
+		modulesDir:      "/lib/modules",
+	}
+
+	tt := []struct {
+		desc        string
+		workerPools []v1alpha1.WorkerPoolStatus
+		want        []pluginGroup
+	}{
+		{
+			desc: "test workerless shoot",
+			want: []pluginGroup{
+				{name: "csi-driver-lvm-plugin", hostWritePath: "/etc/lvm", paths: defaultPaths},
+			},
+		},
+		{
+			desc: "test equal worker pools",
+			workerPools: []v1alpha1.WorkerPoolStatus{
+				{Name: "a", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "b", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+			},
+			want: []pluginGroup{
+				{name: "csi-driver-lvm-plugin", hostWritePath: "/etc/lvm", paths: defaultPaths},
+			},
+		},
+		{
+			desc: "test different worker pools",
+			workerPools: []v1alpha1.WorkerPoolStatus{
+				{Name: "a", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "b", HostWritePath: "/var/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "c", HostWritePath: "/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+			},
+			want: []pluginGroup{
+				{name: "csi-driver-lvm-plugin", pools: []string{"a", "c"}, hostWritePath: "/etc/lvm", paths: defaultPaths},
+				{name: "csi-driver-lvm-plugin-b", pools: []string{"b"}, hostWritePath: "/var/etc/lvm", paths: defaultPaths},
+			},
+		},
+		{
+			desc: "test different worker pools without default settings",
+			workerPools: []v1alpha1.WorkerPoolStatus{
+				{Name: "a", HostWritePath: "/var/etc/lvm", KubeletRootDir: "/var/lib/kubelet"},
+				{Name: "b", HostWritePath: "/opt/lvm", KubeletRootDir: "/var/lib/kubelet"},
+			},
+			want: []pluginGroup{
+				{name: "csi-driver-lvm-plugin-a", pools: []string{"a"}, hostWritePath: "/var/etc/lvm", paths: defaultPaths},
+				{name: "csi-driver-lvm-plugin-b", pools: []string{"b"}, hostWritePath: "/opt/lvm", paths: defaultPaths},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.want, pluginGroups(csidriverlvmConfig, config.ControllerConfiguration{}, tc.workerPools))
+		})
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	tt := []struct {
+		desc             string
+		paths            *v1alpha1.Paths
+		controllerConfig config.ControllerConfiguration
+		want             nodePaths
+	}{
+		{
+			desc: "test derived paths",
+			want: nodePaths{
+				kubeletRootDir:  "/data/kubelet",
+				pluginDir:       "/data/kubelet/plugins/csi-driver-lvm",
+				registrationDir: "/data/kubelet/plugins_registry",
+				devDir:          "/dev",
+				modulesDir:      "/lib/modules",
+			},
+		},
+		{
+			desc: "test operator defaults and shoot paths",
+			paths: &v1alpha1.Paths{
+				RegistrationDir: ptr.To("/var/registry"),
+			},
+			controllerConfig: config.ControllerConfiguration{
+				DefaultPaths: &config.Paths{
+					RegistrationDir: ptr.To("/opt/registry"),
+					ModulesDir:      ptr.To("/usr/lib/modules"),
+				},
+			},
+			want: nodePaths{
+				kubeletRootDir:  "/data/kubelet",
+				pluginDir:       "/data/kubelet/plugins/csi-driver-lvm",
+				registrationDir: "/var/registry",
+				devDir:          "/dev",
+				modulesDir:      "/usr/lib/modules",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := resolvePaths(&v1alpha1.CsiDriverLvmConfig{Paths: tc.paths}, tc.controllerConfig, "/data/kubelet")
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPluginGroupAffinity(t *testing.T) {
+	group := pluginGroup{name: "csi-driver-lvm-plugin"}
+	assert.Nil(t, group.nodeAffinity())
+	assert.Equal(t, []string{"csi-driver-lvm-plugin"}, group.podAffinity().RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchExpressions[0].Values)
+
+	group = pluginGroup{name: "csi-driver-lvm-plugin-a", pools: []string{"a", "c"}}
+	requirement := group.nodeAffinity().RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	assert.Equal(t, v1beta1constants.LabelWorkerPool, requirement.Key)
+	assert.Equal(t, []string{"a", "c"}, requirement.Values)
+	assert.Equal(t, []string{"csi-driver-lvm-plugin-a"}, group.podAffinity().RequiredDuringSchedulingIgnoredDuringExecution[0].LabelSelector.MatchExpressions[0].Values)
+}
@@ -0,0 +1,4 @@
+package version
+
+// Version is the version of the extension, it is set at build time by the linker flags of the Makefile
+var Version = "devel"
@@ -0,0 +1,96 @@
+package operatingsystemconfig
+
+import (
+	"context"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane/genericmutator"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/component/extensions/operatingsystemconfig/original/components/kubelet"
+	oscutils "github.com/gardener/gardener/pkg/component/extensions/operatingsystemconfig/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+const (
+	// WebhookName is the name of the operating system config webhook.
+	WebhookName = "csi-driver-lvm-osc"
+)
+
+var (
+	// DefaultAddOptions are the default AddOptions for AddToManager.
+	DefaultAddOptions = AddOptions{}
+)
+
+// AddOptions are options to apply when adding the operating system config webhook to the manager.
+type AddOptions struct {
+	// Config contains configuration for the csi-driver-lvm.
+	Config config.ControllerConfiguration
+}
+
+// AddToManager creates the operating system config webhook with the default Options.
+func AddToManager(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	return AddToManagerWithOptions(mgr, DefaultAddOptions)
+}
+
+// AddToManagerWithOptions creates the operating system config webhook with the given Options.
+// The webhook only mutates the OperatingSystemConfigs of shoots which have the extension enabled.
+func AddToManagerWithOptions(mgr manager.Manager, opts AddOptions) (*extensionswebhook.Webhook, error) {
+	logger := log.Log.WithName(WebhookName)
+	fciCodec := oscutils.NewFileContentInlineCodec()
+
+	mutator := &workerPoolMutator{mutator: genericmutator.NewMutator(
+		mgr,
+		NewEnsurer(mgr.GetClient(), mgr.GetScheme(), opts.Config, logger),
+		oscutils.NewUnitSerializer(),
+		kubelet.NewConfigCodec(fciCodec),
+		fciCodec,
+		logger,
+	)}
+
+	return extensionswebhook.New(mgr, extensionswebhook.Args{
+		Provider: csidriverlvm.Type,
+		Name:     WebhookName,
+		Path:     "/webhooks/" + WebhookName,
+		Target:   extensionswebhook.TargetSeed,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				v1beta1constants.LabelExtensionExtensionTypePrefix + csidriverlvm.Type: "true",
+			},
+		},
+		Mutators: map[extensionswebhook.Mutator][]extensionswebhook.Type{
+			mutator: {{Obj: &extensionsv1alpha1.OperatingSystemConfig{}}},
+		},
+	})
+}
+
+type workerPoolKey struct{}
+
+// workerPoolMutator passes the worker pool of the mutated OperatingSystemConfig to the ensurer, the ensurer only
+// receives the files and units of the OperatingSystemConfig
+type workerPoolMutator struct {
+	mutator extensionswebhook.Mutator
+}
+
+// Mutate delegates to the generic mutator with the worker pool of the OperatingSystemConfig stored in the context.
+func (m *workerPoolMutator) Mutate(ctx context.Context, new, old client.Object) error {
+	if osc, ok := new.(*extensionsv1alpha1.OperatingSystemConfig); ok {
+		ctx = withWorkerPool(ctx, osc.Labels[v1beta1constants.LabelWorkerPool])
+	}
+	return m.mutator.Mutate(ctx, new, old)
+}
+
+func withWorkerPool(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, workerPoolKey{}, name)
+}
+
+func workerPoolFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(workerPoolKey{}).(string)
+	return name
+}
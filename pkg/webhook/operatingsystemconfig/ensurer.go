@@ -0,0 +1,219 @@
+package operatingsystemconfig
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	extensionscontextwebhook "github.com/gardener/gardener/extensions/pkg/webhook/context"
+	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane/genericmutator"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/v1alpha1"
+	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+const (
+	prepareUnitName       = "csi-driver-lvm-prepare.service"
+	loopDevicesUnitName   = "csi-driver-lvm-loop-devices.service"
+	modulesLoadFilePath   = "/etc/modules-load.d/csi-driver-lvm.conf"
+	loopDevicesScriptPath = "/opt/bin/csi-driver-lvm-loop-devices.sh"
+	loopDevicesDirectory  = "/var/lib/csi-driver-lvm"
+)
+
+// lvmDirectories are the directories below the host write path which are mounted into the plugin
+var lvmDirectories = []string{"cache", "archive", "backup", "lock"}
+
+// NewEnsurer creates a new ensurer which prepares the nodes of shoots with the extension enabled for the csi-driver-lvm.
+func NewEnsurer(c client.Client, scheme *runtime.Scheme, config config.ControllerConfiguration, logger logr.Logger) genericmutator.Ensurer {
+	return &ensurer{
+		client:  c,
+		decoder: serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+		config:  config,
+		logger:  logger.WithName("ensurer"),
+	}
+}
+
+type ensurer struct {
+	genericmutator.NoopEnsurer
+	client  client.Client
+	decoder runtime.Decoder
+	config  config.ControllerConfiguration
+	logger  logr.Logger
+}
+
+// EnsureAdditionalFiles ensures the modules-load.d configuration and, for development shoots, the loop devices script.
+func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx extensionscontextwebhook.GardenContext, new, _ *[]extensionsv1alpha1.File) error {
+	csidriverlvmConfig, _, cluster, err := e.csiDriverLvmConfig(ctx, gctx)
+	if err != nil || csidriverlvmConfig == nil {
+		return err
+	}
+
+	*new = extensionswebhook.EnsureFileWithPath(*new, extensionsv1alpha1.File{
+		Path:        modulesLoadFilePath,
+		Permissions: ptr.To(int32(0644)),
+		Content: extensionsv1alpha1.FileContent{
+			Inline: &extensionsv1alpha1.FileContentInline{
+				Data: strings.Join(nodeKernelModules(csidriverlvmConfig), "\n") + "\n",
+			},
+		},
+	})
+
+	if hasLoopDevices(csidriverlvmConfig, cluster) {
+		*new = extensionswebhook.EnsureFileWithPath(*new, extensionsv1alpha1.File{
+			Path:        loopDevicesScriptPath,
+			Permissions: ptr.To(int32(0755)),
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Data: loopDevicesScript(csidriverlvmConfig.LoopDevices),
+				},
+			},
+		})
+	}
+
+	return nil
+}
+
+// EnsureAdditionalUnits ensures the unit preparing the node and, for development shoots, the unit creating the loop devices.
+func (e *ensurer) EnsureAdditionalUnits(ctx context.Context, gctx extensionscontextwebhook.GardenContext, new, _ *[]extensionsv1alpha1.Unit) error {
+	csidriverlvmConfig, pool, cluster, err := e.csiDriverLvmConfig(ctx, gctx)
+	if err != nil || csidriverlvmConfig == nil {
+		return err
+	}
+
+	extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
+		Name:      prepareUnitName,
+		Command:   ptr.To(extensionsv1alpha1.CommandRestart),
+		Enable:    ptr.To(true),
+		Content:   ptr.To(prepareUnit(csidriverlvmConfig, pool.HostWritePath)),
+		FilePaths: []string{modulesLoadFilePath},
+	})
+
+	if hasLoopDevices(csidriverlvmConfig, cluster) {
+		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
+			Name:    loopDevicesUnitName,
+			Command: ptr.To(extensionsv1alpha1.CommandRestart),
+			Enable:  ptr.To(true),
+			Content: ptr.To(`[Unit]
+Description=Create the loop devices used by csi-driver-lvm
+After=` + prepareUnitName + `
+Before=kubelet.service
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=` + loopDevicesScriptPath + `
+[Install]
+WantedBy=multi-user.target
+`),
+			FilePaths: []string{loopDevicesScriptPath},
+		})
+	}
+
+	return nil
+}
+
+// csiDriverLvmConfig returns the defaulted configuration of the extension in the namespace of the shoot and the settings
+// resolved for the worker pool of the mutated OperatingSystemConfig, the configuration is nil if the extension does not
+// exist, is being deleted or is misconfigured
+func (e *ensurer) csiDriverLvmConfig(ctx context.Context, gctx extensionscontextwebhook.GardenContext) (*v1alpha1.CsiDriverLvmConfig, *v1alpha1.WorkerPoolStatus, *extensionscontroller.Cluster, error) {
+	cluster, err := gctx.GetCluster(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get cluster: %w", err)
+	}
+
+	ex := &extensionsv1alpha1.Extension{}
+	if err := e.client.Get(ctx, client.ObjectKey{Namespace: cluster.ObjectMeta.Name, Name: csidriverlvm.Type}, ex); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, cluster, nil
+		}
+		return nil, nil, nil, fmt.Errorf("failed to get extension: %w", err)
+	}
+	if ex.DeletionTimestamp != nil {
+		return nil, nil, cluster, nil
+	}
+
+	csidriverlvmConfig := &v1alpha1.CsiDriverLvmConfig{}
+	if ex.Spec.ProviderConfig != nil {
+		if _, _, err := e.decoder.Decode(ex.Spec.ProviderConfig.Raw, nil, csidriverlvmConfig); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to decode provider config: %w", err)
+		}
+	}
+
+	poolName := workerPoolFromContext(ctx)
+	pool := csidriverlvm.WorkerPool(csidriverlvm.WorkerPools(csidriverlvmConfig, e.config, cluster), poolName)
+	if pool == nil {
+		e.logger.Info("skipping node preparation of unknown worker pool", "namespace", ex.Namespace, "pool", poolName)
+		return nil, nil, cluster, nil
+	}
+
+	csidriverlvmConfig.ConfigureDefaults(e.config.DefaultHostWritePath, e.config.DefaultDevicePattern)
+
+	// an invalid configuration is reported by the extension controller, the nodes must not be blocked by it
+	if !csidriverlvmConfig.IsValid(e.logger) {
+		e.logger.Info("skipping node preparation because of an invalid configuration", "namespace", ex.Namespace)
+		return nil, nil, cluster, nil
+	}
+
+	return csidriverlvmConfig, pool, cluster, nil
+}
+
+// nodeKernelModules returns the kernel modules loaded on the nodes, dm_mirror is needed by the default mirror storage class
+func nodeKernelModules(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig) []string {
+	return append([]string{"dm_mirror"}, csidriverlvm.KernelModules(csidriverlvmConfig)...)
+}
+
+func prepareUnit(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, hostWritePath string) string {
+	directories := make([]string, 0, len(lvmDirectories))
+	for _, directory := range lvmDirectories {
+		directories = append(directories, hostWritePath+"/"+directory)
+	}
+
+	return `[Unit]
+Description=Prepare the node for csi-driver-lvm
+Before=kubelet.service
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/env modprobe -a ` + strings.Join(nodeKernelModules(csidriverlvmConfig), " ") + `
+ExecStart=/usr/bin/env mkdir -p ` + strings.Join(directories, " ") + `
+[Install]
+WantedBy=multi-user.target
+`
+}
+
+func hasLoopDevices(csidriverlvmConfig *v1alpha1.CsiDriverLvmConfig, cluster *extensionscontroller.Cluster) bool {
+	return csidriverlvmConfig.LoopDevices != nil &&
+		cluster.Shoot != nil &&
+		ptr.Deref(cluster.Shoot.Spec.Purpose, "") == gardencorev1beta1.ShootPurposeDevelopment
+}
+
+// loopDevicesScript returns a script which creates the backing files and attaches them to free loop devices,
+// existing files and attached loop devices are kept so that the script can be run on every boot
+func loopDevicesScript(loopDevices *v1alpha1.LoopDevices) string {
+	return fmt.Sprintf(`#!/bin/bash
+set -o errexit
+set -o nounset
+set -o pipefail
+
+mkdir -p %[1]s
+for i in $(seq 1 %[2]d); do
+  file="%[1]s/loop${i}.img"
+  if [ ! -f "${file}" ]; then
+    truncate --size %[3]d "${file}"
+  fi
+  if [ -z "$(losetup --associated "${file}")" ]; then
+    losetup --find "${file}"
+  fi
+done
+`, loopDevicesDirectory, loopDevices.Count, loopDevices.Size.Value())
+}
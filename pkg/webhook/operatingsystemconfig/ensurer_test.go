@@ -0,0 +1,204 @@
+package operatingsystemconfig
+
+import (
+	"context"
+	"testing"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionscontextwebhook "github.com/gardener/gardener/extensions/pkg/webhook/context"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/csidriverlvm/install"
+)
+
+func TestEnsurer(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, extensionscontroller.AddToScheme(scheme))
+	require.NoError(t, install.AddToScheme(scheme))
+
+	controllerConfig := config.ControllerConfiguration{
+		DefaultDevicePattern: ptr.To("/dev/nvme[0-1]n[0-9]"),
+		DefaultHostWritePath: ptr.To("/etc/lvm"),
+		MachineImageDefaults: []config.MachineImageDefaults{
+			{Name: "talos", HostWritePath: ptr.To("/var/etc/lvm")},
+		},
+	}
+
+	tt := []struct {
+		desc           string
+		providerConfig string
+		purpose        gardencorev1beta1.ShootPurpose
+		pool           string
+		noExtension    bool
+		wantUnits      []string
+		wantFiles      []string
+		wantContent    map[string]string
+	}{
+		{
+			desc:        "test without extension",
+			noExtension: true,
+		},
+		{
+			desc:      "test default config",
+			wantUnits: []string{prepareUnitName},
+			wantFiles: []string{modulesLoadFilePath},
+			wantContent: map[string]string{
+				modulesLoadFilePath: "dm_mirror\n",
+			},
+		},
+		{
+			desc:      "test machine image default",
+			pool:      "talos",
+			wantUnits: []string{prepareUnitName},
+			wantFiles: []string{modulesLoadFilePath},
+			wantContent: map[string]string{
+				prepareUnitName: `[Unit]
+Description=Prepare the node for csi-driver-lvm
+Before=kubelet.service
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/env modprobe -a dm_mirror
+ExecStart=/usr/bin/env mkdir -p /var/etc/lvm/cache /var/etc/lvm/archive /var/etc/lvm/backup /var/etc/lvm/lock
+[Install]
+WantedBy=multi-user.target
+`,
+			},
+		},
+		{
+			desc:           "test thin and raid storage classes",
+			providerConfig: `{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","hostWritePath":"/opt/lvm","storageClasses":[{"name":"thin","type":"thin"},{"name":"raid","type":"raid1"}]}`,
+			pool:           "talos",
+			wantUnits:      []string{prepareUnitName},
+			wantFiles:      []string{modulesLoadFilePath},
+			wantContent: map[string]string{
+				modulesLoadFilePath: "dm_mirror\ndm_thin_pool\ndm_raid\n",
+				prepareUnitName: `[Unit]
+Description=Prepare the node for csi-driver-lvm
+Before=kubelet.service
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/usr/bin/env modprobe -a dm_mirror dm_thin_pool dm_raid
+ExecStart=/usr/bin/env mkdir -p /opt/lvm/cache /opt/lvm/archive /opt/lvm/backup /opt/lvm/lock
+[Install]
+WantedBy=multi-user.target
+`,
+			},
+		},
+		{
+			desc:           "test loop devices in development shoot",
+			providerConfig: `{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","devicePattern":"/dev/loop*","loopDevices":{"count":2,"size":"1Gi"}}`,
+			purpose:        gardencorev1beta1.ShootPurposeDevelopment,
+			wantUnits:      []string{prepareUnitName, loopDevicesUnitName},
+			wantFiles:      []string{modulesLoadFilePath, loopDevicesScriptPath},
+		},
+		{
+			desc:           "test loop devices in production shoot",
+			providerConfig: `{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","devicePattern":"/dev/loop*","loopDevices":{"count":2,"size":"1Gi"}}`,
+			purpose:        gardencorev1beta1.ShootPurposeProduction,
+			wantUnits:      []string{prepareUnitName},
+			wantFiles:      []string{modulesLoadFilePath},
+		},
+		{
+			desc:           "test invalid config",
+			providerConfig: `{"apiVersion":"csi-driver-lvm.metal.extensions.gardener.cloud/v1alpha1","kind":"CsiDriverLvmConfig","hostWritePath":"etc/lvm"}`,
+		},
+		{
+			desc: "test unknown worker pool",
+			pool: "unknown",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			var objects []client.Object
+			if !tc.noExtension {
+				ex := &extensionsv1alpha1.Extension{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--test--test", Name: "csi-driver-lvm"},
+				}
+				if tc.providerConfig != "" {
+					ex.Spec.ProviderConfig = &runtime.RawExtension{Raw: []byte(tc.providerConfig)}
+				}
+				objects = append(objects, ex)
+			}
+
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			gctx := extensionscontextwebhook.NewInternalGardenContext(&extensionscontroller.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "shoot--test--test"},
+				Shoot: &gardencorev1beta1.Shoot{
+					Spec: gardencorev1beta1.ShootSpec{
+						Purpose: ptr.To(tc.purpose),
+						Provider: gardencorev1beta1.Provider{
+							Workers: []gardencorev1beta1.Worker{
+								{Name: "default", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "gardenlinux"}}},
+								{Name: "talos", Machine: gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: "talos"}}},
+							},
+						},
+					},
+				},
+			})
+			e := NewEnsurer(c, scheme, controllerConfig, logr.Discard())
+
+			pool := tc.pool
+			if pool == "" {
+				pool = "default"
+			}
+			ctx := withWorkerPool(context.Background(), pool)
+
+			units := []extensionsv1alpha1.Unit{{Name: "kubelet.service"}}
+			require.NoError(t, e.EnsureAdditionalUnits(ctx, gctx, &units, nil))
+			files := []extensionsv1alpha1.File{}
+			require.NoError(t, e.EnsureAdditionalFiles(ctx, gctx, &files, nil))
+
+			unitNames := []string{}
+			for _, unit := range units[1:] {
+				unitNames = append(unitNames, unit.Name)
+				if content, ok := tc.wantContent[unit.Name]; ok {
+					assert.Equal(t, content, ptr.Deref(unit.Content, ""))
+				}
+			}
+			filePaths := []string{}
+			for _, file := range files {
+				filePaths = append(filePaths, file.Path)
+				if content, ok := tc.wantContent[file.Path]; ok {
+					assert.Equal(t, content, file.Content.Inline.Data)
+				}
+			}
+
+			assert.ElementsMatch(t, tc.wantUnits, unitNames)
+			assert.ElementsMatch(t, tc.wantFiles, filePaths)
+		})
+	}
+}
+
+type recordingMutator struct {
+	pool string
+}
+
+func (m *recordingMutator) Mutate(ctx context.Context, _, _ client.Object) error {
+	m.pool = workerPoolFromContext(ctx)
+	return nil
+}
+
+func TestWorkerPoolMutator(t *testing.T) {
+	recorder := &recordingMutator{}
+	m := &workerPoolMutator{mutator: recorder}
+
+	osc := &extensionsv1alpha1.OperatingSystemConfig{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1constants.LabelWorkerPool: "talos"}},
+	}
+	require.NoError(t, m.Mutate(context.Background(), osc, nil))
+	assert.Equal(t, "talos", recorder.pool)
+}
@@ -0,0 +1,69 @@
+package provisioner
+
+import (
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/apis/config"
+	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+const (
+	// WebhookName is the name of the provisioner webhook.
+	WebhookName = "csi-driver-lvm-provisioner"
+)
+
+var (
+	// DefaultAddOptions are the default AddOptions for AddToManager.
+	DefaultAddOptions = AddOptions{}
+)
+
+// AddOptions are options to apply when adding the provisioner webhook to the manager.
+type AddOptions struct {
+	// Config contains configuration for the csi-driver-lvm.
+	Config config.ControllerConfiguration
+}
+
+// AddToManager creates the provisioner webhook with the default Options.
+func AddToManager(mgr manager.Manager) (*extensionswebhook.Webhook, error) {
+	return AddToManagerWithOptions(mgr, DefaultAddOptions)
+}
+
+// AddToManagerWithOptions creates the provisioner webhook with the given Options.
+// The webhook is deployed into the shoots which configure an lvmlocal.conf, it mutates the provisioner pods started by
+// the plugin in the kube-system namespace.
+func AddToManagerWithOptions(mgr manager.Manager, opts AddOptions) (*extensionswebhook.Webhook, error) {
+	repositories, err := csidriverlvm.ProvisionerImageRepositories(opts.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := extensionswebhook.New(mgr, extensionswebhook.Args{
+		Provider: csidriverlvm.Type,
+		Name:     WebhookName,
+		Path:     "/webhooks/" + WebhookName,
+		Target:   extensionswebhook.TargetShoot,
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: v1beta1constants.GardenerPurpose, Operator: metav1.LabelSelectorOpIn, Values: []string{metav1.NamespaceSystem}},
+			},
+		},
+		Mutators: map[extensionswebhook.Mutator][]extensionswebhook.Type{
+			NewMutator(repositories, log.Log.WithName(WebhookName)): {{Obj: &corev1.Pod{}}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the webhook receives all pods of the kube-system namespace, it must not block them if it is not reachable
+	webhook.FailurePolicy = ptr.To(admissionregistrationv1.Ignore)
+
+	return webhook, nil
+}
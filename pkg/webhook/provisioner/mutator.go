@@ -0,0 +1,92 @@
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+const (
+	lvmConfigVolumeName string = "lvm-config"
+	lvmConfigMountPath  string = "/etc/lvm/" + csidriverlvm.LvmConfigKey
+)
+
+// NewMutator creates a new mutator passing the lvmlocal.conf to the provisioner pods started with an image of the
+// given repositories.
+func NewMutator(repositories []string, logger logr.Logger) extensionswebhook.Mutator {
+	return &mutator{
+		repositories: repositories,
+		logger:       logger,
+	}
+}
+
+type mutator struct {
+	repositories []string
+	logger       logr.Logger
+}
+
+// Mutate mounts the lvmlocal.conf of the plugin into the containers of new provisioner pods, the provisioner pods are
+// started by the plugin and create and remove the logical volumes.
+func (m *mutator) Mutate(_ context.Context, new, old client.Object) error {
+	// the volumes of a pod are immutable
+	if old != nil || new.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	pod, ok := new.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("wrong object type %T", new)
+	}
+
+	mutated := false
+	for i, container := range pod.Spec.Containers {
+		if !m.isProvisionerImage(container.Image) {
+			continue
+		}
+		if slices.ContainsFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool { return mount.MountPath == lvmConfigMountPath }) {
+			continue
+		}
+
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      lvmConfigVolumeName,
+			MountPath: lvmConfigMountPath,
+			SubPath:   csidriverlvm.LvmConfigKey,
+			ReadOnly:  true,
+		})
+		mutated = true
+	}
+
+	if !mutated || slices.ContainsFunc(pod.Spec.Volumes, func(volume corev1.Volume) bool { return volume.Name == lvmConfigVolumeName }) {
+		return nil
+	}
+
+	m.logger.Info("mounting lvmlocal.conf into provisioner pod", "pod", client.ObjectKeyFromObject(pod))
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: lvmConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: csidriverlvm.LvmConfigMapName},
+				// the config map is removed together with the webhook, pods created in between must not be blocked
+				Optional: ptr.To(true),
+			},
+		},
+	})
+
+	return nil
+}
+
+// isProvisionerImage returns true if the image belongs to one of the repositories of the provisioner images
+func (m *mutator) isProvisionerImage(image string) bool {
+	return slices.ContainsFunc(m.repositories, func(repository string) bool {
+		return image == repository || strings.HasPrefix(image, repository+":") || strings.HasPrefix(image, repository+"@")
+	})
+}
@@ -0,0 +1,87 @@
+package provisioner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	csidriverlvm "github.com/metal-stack/gardener-extension-csi-driver-lvm/pkg/controller/csi-driver-lvm"
+)
+
+func TestMutate(t *testing.T) {
+	pod := func(images ...string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: "create-pvc-1234"}}
+		for _, image := range images {
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "csi-lvmplugin", Image: image})
+		}
+		return pod
+	}
+
+	lvmConfigVolume := corev1.Volume{
+		Name: lvmConfigVolumeName,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: csidriverlvm.LvmConfigMapName},
+			Optional:             ptr.To(true),
+		}},
+	}
+	lvmConfigMount := corev1.VolumeMount{Name: lvmConfigVolumeName, MountPath: "/etc/lvm/lvmlocal.conf", SubPath: "lvmlocal.conf", ReadOnly: true}
+
+	tt := []struct {
+		desc        string
+		pod         *corev1.Pod
+		old         client.Object
+		wantVolumes []corev1.Volume
+		wantMounts  [][]corev1.VolumeMount
+	}{
+		{
+			desc:        "test provisioner pod",
+			pod:         pod("ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0"),
+			wantVolumes: []corev1.Volume{lvmConfigVolume},
+			wantMounts:  [][]corev1.VolumeMount{{lvmConfigMount}},
+		},
+		{
+			desc:        "test provisioner pod with stable image",
+			pod:         pod("registry.example.com/csi-driver-lvm-provisioner@sha256:abc"),
+			wantVolumes: []corev1.Volume{lvmConfigVolume},
+			wantMounts:  [][]corev1.VolumeMount{{lvmConfigMount}},
+		},
+		{
+			desc:       "test other pod",
+			pod:        pod("ghcr.io/metal-stack/csi-driver-lvm:v0.6.0"),
+			wantMounts: [][]corev1.VolumeMount{nil},
+		},
+		{
+			desc:       "test image with the repository as prefix",
+			pod:        pod("ghcr.io/metal-stack/csi-driver-lvm-provisioner-debug:v0.6.0"),
+			wantMounts: [][]corev1.VolumeMount{nil},
+		},
+		{
+			desc:       "test update of a provisioner pod",
+			pod:        pod("ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0"),
+			old:        pod("ghcr.io/metal-stack/csi-driver-lvm-provisioner:v0.6.0"),
+			wantMounts: [][]corev1.VolumeMount{nil},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.desc, func(t *testing.T) {
+			m := NewMutator([]string{"ghcr.io/metal-stack/csi-driver-lvm-provisioner", "registry.example.com/csi-driver-lvm-provisioner"}, logr.Discard())
+
+			require.NoError(t, m.Mutate(context.Background(), tc.pod, tc.old))
+			// the mutation must be idempotent, the api server may call the webhook again
+			require.NoError(t, m.Mutate(context.Background(), tc.pod, tc.old))
+
+			assert.Equal(t, tc.wantVolumes, tc.pod.Spec.Volumes)
+			for i, container := range tc.pod.Spec.Containers {
+				assert.Equal(t, tc.wantMounts[i], container.VolumeMounts)
+			}
+		})
+	}
+}